@@ -0,0 +1,78 @@
+package ievio
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+var ErrAbsCode = errors.New("ievio: ABS code out of range")
+
+// The request numbers of EVIOCGABS and EVIOCSABS hold the axis in their
+// 8-bit nr field, which codes above ABS_MAX would overflow.
+func eviocgabs(code ABS_CODE) uintptr {
+	return ioctl.IOR('E', 0x40+uintptr(code), unsafe.Sizeof(AbsInfo{}))
+}
+
+func eviocsabs(code ABS_CODE) uintptr {
+	return ioctl.IOW('E', 0xc0+uintptr(code), unsafe.Sizeof(AbsInfo{}))
+}
+
+// AbsInfo mirrors struct input_absinfo. Resolution is in units per
+// millimeter for positions, or units per radian for ABS_*_ORIENTATION.
+type AbsInfo struct {
+	Value      int32
+	Minimum    int32
+	Maximum    int32
+	Fuzz       int32
+	Flat       int32
+	Resolution int32
+}
+
+func (d *Device) AbsInfo(code ABS_CODE) (AbsInfo, error) {
+	var info AbsInfo
+	if code > ABS_MAX {
+		return info, ErrAbsCode
+	}
+
+	err := d.ioctlPtr(eviocgabs(code), unsafe.Pointer(&info))
+	return info, err
+}
+
+// SetAbsInfo overrides the range of an axis, e.g. after calibration.
+func (d *Device) SetAbsInfo(code ABS_CODE, info AbsInfo) error {
+	if code > ABS_MAX {
+		return ErrAbsCode
+	}
+
+	return d.ioctlPtr(eviocsabs(code), unsafe.Pointer(&info))
+}
+
+// Normalize maps value from [Minimum, Maximum] to [-1, 1], clamping
+// values outside of the range.
+func (v AbsInfo) Normalize(value int32) float64 {
+	if v.Maximum <= v.Minimum {
+		return 0
+	}
+
+	n := 2*float64(int64(value)-int64(v.Minimum))/float64(int64(v.Maximum)-int64(v.Minimum)) - 1
+	if n < -1 {
+		return -1
+	}
+	if n > 1 {
+		return 1
+	}
+
+	return n
+}
+
+// Millimeters returns the distance of value from Minimum in millimeters.
+// It reports false if the device does not declare a resolution.
+func (v AbsInfo) Millimeters(value int32) (float64, bool) {
+	if v.Resolution <= 0 {
+		return 0, false
+	}
+
+	return float64(int64(value)-int64(v.Minimum)) / float64(v.Resolution), true
+}
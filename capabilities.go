@@ -0,0 +1,176 @@
+package ievio
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"unsafe"
+
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+const eviocgbit = 0x20
+
+// Bitset holds one bit per code, bit n being bit n%8 of byte n/8.
+type Bitset []byte
+
+func NewBitset(n int) Bitset {
+	return make(Bitset, (n+7)/8)
+}
+
+func (b Bitset) Has(n uint16) bool {
+	return int(n/8) < len(b) && b[n/8]&(1<<(n%8)) != 0
+}
+
+func (b Bitset) Set(n uint16) {
+	if int(n/8) < len(b) {
+		b[n/8] |= 1 << (n % 8)
+	}
+}
+
+// Bits returns the set bits in ascending order.
+func (b Bitset) Bits() []uint16 {
+	var v []uint16
+	for i, c := range b {
+		for ; c != 0; c &= c - 1 {
+			v = append(v, uint16(i*8+bits.TrailingZeros8(c)))
+		}
+	}
+
+	return v
+}
+
+// bitsetFromLongs converts a kernel bitmap, an array of unsigned longs in
+// native byte order, into a Bitset.
+func bitsetFromLongs(buf []byte) Bitset {
+	b := make(Bitset, len(buf))
+	word := bits.UintSize / 8
+	for i := 0; i+word <= len(buf); i += word {
+		if word == 8 {
+			binary.LittleEndian.PutUint64(b[i:], binary.NativeEndian.Uint64(buf[i:]))
+		} else {
+			binary.LittleEndian.PutUint32(b[i:], binary.NativeEndian.Uint32(buf[i:]))
+		}
+	}
+
+	return b
+}
+
+// longsSize returns the size in bytes of a kernel bitmap of n bits.
+func longsSize(n int) int {
+	word := bits.UintSize
+	return (n + word - 1) / word * (word / 8)
+}
+
+// Capabilities lists the event types of a device and, for each of them,
+// the codes it may emit.
+type Capabilities struct {
+	Types Bitset
+	Bits  map[EV_TYPE]Bitset
+}
+
+// bitTypes are the event types whose codes EVIOCGBIT reports.
+var bitTypes = []EV_TYPE{EV_KEY, EV_REL, EV_ABS, EV_MSC, EV_SW, EV_LED, EV_SND, EV_FF}
+
+func (d *Device) Capabilities() (*Capabilities, error) {
+	types, err := d.bitmap(eviocgbit+uintptr(EV_SYN), EV_CNT)
+	if err != nil {
+		return nil, err
+	}
+
+	caps := &Capabilities{
+		Types: types,
+		Bits:  map[EV_TYPE]Bitset{},
+	}
+	for _, eventType := range bitTypes {
+		if !types.Has(uint16(eventType)) {
+			continue
+		}
+
+		codes, err := d.bitmap(eviocgbit+uintptr(eventType), codeCounts[eventType])
+		if err != nil {
+			return nil, err
+		}
+		caps.Bits[eventType] = codes
+	}
+
+	return caps, nil
+}
+
+// bitmap reads a bitmap of n bits with the EVIOC* request number nr.
+func (d *Device) bitmap(nr uintptr, n int) (Bitset, error) {
+	buf := make([]byte, longsSize(n))
+	req := ioctl.IOC(ioctl.Read, 'E', nr, uintptr(len(buf)))
+	if err := d.ioctlPtr(req, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, err
+	}
+
+	return bitsetFromLongs(buf), nil
+}
+
+// Supports reports whether eventType, and code unless it is nil, are
+// supported.
+func (c *Capabilities) Supports(eventType EV_TYPE, code Code) bool {
+	if !c.Types.Has(uint16(eventType)) {
+		return false
+	}
+
+	if code == nil {
+		return true
+	}
+
+	return c.Bits[eventType].Has(code.ValueUint16())
+}
+
+func (c *Capabilities) EventTypes() []EV_TYPE {
+	return bitsAs[EV_TYPE](c.Types)
+}
+
+func (c *Capabilities) Codes(eventType EV_TYPE) []Code {
+	var v []Code
+	for _, n := range c.Bits[eventType].Bits() {
+		v = append(v, newCode(eventType, n))
+	}
+
+	return v
+}
+
+func (c *Capabilities) Keys() []KEY_CODE {
+	return bitsAs[KEY_CODE](c.Bits[EV_KEY])
+}
+
+func (c *Capabilities) Rels() []REL_CODE {
+	return bitsAs[REL_CODE](c.Bits[EV_REL])
+}
+
+func (c *Capabilities) Abs() []ABS_CODE {
+	return bitsAs[ABS_CODE](c.Bits[EV_ABS])
+}
+
+func (c *Capabilities) Miscs() []MSC_CODE {
+	return bitsAs[MSC_CODE](c.Bits[EV_MSC])
+}
+
+func (c *Capabilities) Switches() []SW_CODE {
+	return bitsAs[SW_CODE](c.Bits[EV_SW])
+}
+
+func (c *Capabilities) LEDs() []LED_CODE {
+	return bitsAs[LED_CODE](c.Bits[EV_LED])
+}
+
+func (c *Capabilities) Sounds() []SND_CODE {
+	return bitsAs[SND_CODE](c.Bits[EV_SND])
+}
+
+func (c *Capabilities) FF() []FF_CODE {
+	return bitsAs[FF_CODE](c.Bits[EV_FF])
+}
+
+func bitsAs[T ~uint16](b Bitset) []T {
+	var v []T
+	for _, n := range b.Bits() {
+		v = append(v, T(n))
+	}
+
+	return v
+}
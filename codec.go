@@ -0,0 +1,102 @@
+package ievio
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// InputEventSize is the size of struct input_event on the running
+// platform: a struct timeval made of two longs, followed by __u16 type,
+// __u16 code and __s32 value.
+const InputEventSize = 2*(bits.UintSize/8) + 8
+
+// Codec converts between InputEvent and the binary input_event record of
+// some platform. Encode panics if buf is shorter than Size.
+type Codec interface {
+	Size() int
+	Encode(input InputEvent, buf []byte)
+	Decode(buf []byte) (InputEvent, error)
+}
+
+// TimeLayout selects how the seconds of an input_event timestamp are
+// interpreted where a C long is 32 bits wide. The record size is the same
+// for both; it has no effect on 64-bit layouts.
+type TimeLayout int
+
+const (
+	// TimeLayout64 reads the seconds as the unsigned __sec field that the
+	// kernel declares since 4.16 for time64 userspace, valid until 2106.
+	// Older kernels wrap the same bits in 2038, so this is the default.
+	TimeLayout64 TimeLayout = iota
+	// TimeLayoutLegacy reads the seconds as the signed tv_sec of struct
+	// timeval, which overflows in January 2038.
+	TimeLayoutLegacy
+)
+
+// LayoutCodec is the Codec of struct input_event for a C long of Word
+// bytes (4 or 8) stored in the given byte order.
+type LayoutCodec struct {
+	Order      binary.ByteOrder
+	Word       int
+	TimeLayout TimeLayout
+}
+
+var (
+	Codec32LE = LayoutCodec{Order: binary.LittleEndian, Word: 4} // 386, arm, mipsle
+	Codec32BE = LayoutCodec{Order: binary.BigEndian, Word: 4}    // mips
+	Codec64LE = LayoutCodec{Order: binary.LittleEndian, Word: 8} // amd64, arm64, riscv64, ppc64le
+	Codec64BE = LayoutCodec{Order: binary.BigEndian, Word: 8}    // mips64, ppc64, s390x
+
+	Codec32LELegacy = LayoutCodec{Order: binary.LittleEndian, Word: 4, TimeLayout: TimeLayoutLegacy}
+	Codec32BELegacy = LayoutCodec{Order: binary.BigEndian, Word: 4, TimeLayout: TimeLayoutLegacy}
+
+	NativeCodec Codec = LayoutCodec{Order: binary.NativeEndian, Word: bits.UintSize / 8}
+)
+
+func (c LayoutCodec) Size() int {
+	return 2*c.Word + 8
+}
+
+func (c LayoutCodec) Decode(buf []byte) (InputEvent, error) {
+	input := InputEvent{}
+	if len(buf) < c.Size() {
+		return input, &TruncatedError{Len: len(buf), Size: c.Size()}
+	}
+
+	if c.Word == 8 {
+		input.Time.Sec = int64(c.Order.Uint64(buf))
+		input.Time.Usec = int64(c.Order.Uint64(buf[8:]))
+	} else if c.TimeLayout == TimeLayout64 {
+		input.Time.Sec = int64(c.Order.Uint32(buf))
+		input.Time.Usec = int64(c.Order.Uint32(buf[4:]))
+	} else {
+		input.Time.Sec = int64(int32(c.Order.Uint32(buf)))
+		input.Time.Usec = int64(int32(c.Order.Uint32(buf[4:])))
+	}
+
+	buf = buf[2*c.Word:]
+	input.Type = EV_TYPE(c.Order.Uint16(buf))
+	input.Code = newCode(input.Type, c.Order.Uint16(buf[2:]))
+	input.Value = int32(c.Order.Uint32(buf[4:]))
+	return input, nil
+}
+
+func (c LayoutCodec) Encode(input InputEvent, buf []byte) {
+	var code uint16
+	if input.Code != nil {
+		code = input.Code.ValueUint16()
+	}
+
+	if c.Word == 8 {
+		c.Order.PutUint64(buf, uint64(input.Time.Sec))
+		c.Order.PutUint64(buf[8:], uint64(input.Time.Usec))
+	} else {
+		c.Order.PutUint32(buf, uint32(input.Time.Sec))
+		c.Order.PutUint32(buf[4:], uint32(input.Time.Usec))
+	}
+
+	buf = buf[2*c.Word:]
+	c.Order.PutUint16(buf, uint16(input.Type))
+	c.Order.PutUint16(buf[2:], code)
+	c.Order.PutUint32(buf[4:], uint32(input.Value))
+}
@@ -0,0 +1,161 @@
+package ievio
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Seconds past 2^31, i.e. after January 2038, with a negative REL_WHEEL.
+const fixtureSec = 0x80000001
+
+var codecFixtures = []struct {
+	name  string
+	codec Codec
+	sec   int64
+	data  []byte
+}{
+	{
+		name:  "32LE",
+		codec: Codec32LE,
+		sec:   fixtureSec,
+		data: []byte{
+			0x01, 0x00, 0x00, 0x80,
+			0x3f, 0x42, 0x0f, 0x00,
+			0x02, 0x00, 0x08, 0x00,
+			0xfd, 0xff, 0xff, 0xff,
+		},
+	},
+	{
+		name:  "32BE",
+		codec: Codec32BE,
+		sec:   fixtureSec,
+		data: []byte{
+			0x80, 0x00, 0x00, 0x01,
+			0x00, 0x0f, 0x42, 0x3f,
+			0x00, 0x02, 0x00, 0x08,
+			0xff, 0xff, 0xff, 0xfd,
+		},
+	},
+	{
+		name:  "64LE",
+		codec: Codec64LE,
+		sec:   fixtureSec,
+		data: []byte{
+			0x01, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00,
+			0x3f, 0x42, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x02, 0x00, 0x08, 0x00,
+			0xfd, 0xff, 0xff, 0xff,
+		},
+	},
+	{
+		name:  "64BE",
+		codec: Codec64BE,
+		sec:   fixtureSec,
+		data: []byte{
+			0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x01,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x0f, 0x42, 0x3f,
+			0x00, 0x02, 0x00, 0x08,
+			0xff, 0xff, 0xff, 0xfd,
+		},
+	},
+	{
+		// The legacy layout reads the same bits as a signed tv_sec, which
+		// has wrapped around to 1901.
+		name:  "32LELegacy",
+		codec: Codec32LELegacy,
+		sec:   fixtureSec - 1<<32,
+		data: []byte{
+			0x01, 0x00, 0x00, 0x80,
+			0x3f, 0x42, 0x0f, 0x00,
+			0x02, 0x00, 0x08, 0x00,
+			0xfd, 0xff, 0xff, 0xff,
+		},
+	},
+}
+
+func fixtureEvent(sec int64) InputEvent {
+	return InputEvent{
+		Time:  Timeval{Sec: sec, Usec: 999999},
+		Type:  EV_REL,
+		Code:  NewRelCode(REL_WHEEL),
+		Value: -3,
+	}
+}
+
+func sameEvent(a, b InputEvent) bool {
+	return a.Time == b.Time && a.Type == b.Type && a.Value == b.Value &&
+		a.Code.ValueUint16() == b.Code.ValueUint16() && a.Code.String() == b.Code.String()
+}
+
+func TestCodecDecode(t *testing.T) {
+	for _, f := range codecFixtures {
+		if n := f.codec.Size(); n != len(f.data) {
+			t.Errorf("%s: Size() = %d, want %d", f.name, n, len(f.data))
+			continue
+		}
+
+		got, err := f.codec.Decode(f.data)
+		if err != nil {
+			t.Errorf("%s: Decode: %v", f.name, err)
+			continue
+		}
+		if want := fixtureEvent(f.sec); !sameEvent(got, want) {
+			t.Errorf("%s: Decode = %v, want %v", f.name, &got, &want)
+		}
+	}
+}
+
+func TestCodecEncode(t *testing.T) {
+	for _, f := range codecFixtures {
+		buf := make([]byte, f.codec.Size())
+		f.codec.Encode(fixtureEvent(fixtureSec), buf)
+		if !bytes.Equal(buf, f.data) {
+			t.Errorf("%s: Encode = % x, want % x", f.name, buf, f.data)
+		}
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, f := range codecFixtures {
+		input, err := f.codec.Decode(f.data)
+		if err != nil {
+			t.Errorf("%s: Decode: %v", f.name, err)
+			continue
+		}
+
+		buf := make([]byte, f.codec.Size())
+		f.codec.Encode(input, buf)
+		if !bytes.Equal(buf, f.data) {
+			t.Errorf("%s: Encode(Decode(data)) = % x, want % x", f.name, buf, f.data)
+		}
+	}
+}
+
+func TestCodecDecodeShort(t *testing.T) {
+	var te *TruncatedError
+	if _, err := Codec64LE.Decode(make([]byte, 23)); !errors.As(err, &te) || te.Len != 23 || te.Size != 24 {
+		t.Errorf("Decode(23 bytes) = %v, want TruncatedError{23, 24}", err)
+	}
+}
+
+func TestReaderForeignCodec(t *testing.T) {
+	for _, f := range codecFixtures {
+		data := append(append(append([]byte{}, f.data...), f.data...), f.data[:5]...)
+		r := NewReader(bytes.NewReader(data), f.codec)
+		for i := 0; i < 2; i++ {
+			input, err := r.ReadEvent()
+			if err != nil {
+				t.Fatalf("%s: ReadEvent %d: %v", f.name, i, err)
+			}
+			if want := fixtureEvent(f.sec); !sameEvent(*input, want) {
+				t.Errorf("%s: ReadEvent %d = %v, want %v", f.name, i, input, &want)
+			}
+		}
+
+		var te *TruncatedError
+		if _, err := r.ReadEvent(); !errors.As(err, &te) || te.Len != 5 {
+			t.Errorf("%s: ReadEvent on 5 trailing bytes = %v, want TruncatedError", f.name, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type EV_TYPE uint16
@@ -25,14 +26,32 @@ const (
 	EV_CNT               = (EV_MAX + 1)
 )
 
-func stringToUint64(s string, bitSize int) (uint64, error) {
+// stringToInt64 parses decimal, "0x" hexadecimal and "0o" octal numbers.
+// A bare leading zero does not mean octal, so "010" is 10.
+func stringToInt64(s string, bitSize int) (int64, error) {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
 	if strings.HasPrefix(strings.ToLower(s), "0x") {
-		return strconv.ParseUint(string([]rune(s)[2:]), 16, bitSize)
+		return strconv.ParseInt(sign+string([]rune(s)[2:]), 16, bitSize)
+	} else if strings.HasPrefix(strings.ToLower(s), "0o") {
+		return strconv.ParseInt(sign+string([]rune(s)[2:]), 8, bitSize)
 	} else {
-		return strconv.ParseUint(s, 10, bitSize)
+		return strconv.ParseInt(sign+s, 10, bitSize)
 	}
 }
 
+func stringToUint16(s string) (uint16, bool) {
+	v, err := stringToInt64(s, 32)
+	if err != nil || v < 0 || v > 0xffff {
+		return 0, false
+	}
+
+	return uint16(v), true
+}
+
 func (v EV_TYPE) String() string {
 	switch v {
 	case EV_SYN:
@@ -68,13 +87,19 @@ func (v EV_TYPE) String() string {
 	return fmt.Sprintf("UNKNOWN(0x%04x)", uint16(v))
 }
 
-func getEventTypeFromString(s string) (EV_TYPE, error) {
-	v, err := stringToUint64(s, 16)
-	if err != nil {
-		return EV_CNT, err
+// ParseEventType accepts an event type name such as "EV_KEY", compared
+// case-insensitively, or a decimal, "0x" hexadecimal or "0o" octal number.
+func ParseEventType(s string) (EV_TYPE, error) {
+	if v, ok := stringToUint16(s); ok {
+		return EV_TYPE(v), nil
+	}
+
+	nameIndexOnce.Do(initNameIndex)
+	if v, ok := eventTypeNames[strings.ToUpper(s)]; ok {
+		return v, nil
 	}
 
-	return EV_TYPE(uint16(v)), nil
+	return EV_CNT, fmt.Errorf("ievio: unknown event type %q", s)
 }
 
 type Code interface {
@@ -82,44 +107,128 @@ type Code interface {
 	String() string
 }
 
-func getCodeFromString(eventType EV_TYPE, s string) (Code, error) {
-	v, err := stringToUint64(s, 16)
-	if err != nil {
-		return nil, err
+// ParseCode accepts a code name of eventType such as "KEY_A" or
+// "ABS_MT_SLOT", compared case-insensitively, or a decimal, "0x"
+// hexadecimal or "0o" octal number.
+func ParseCode(eventType EV_TYPE, s string) (Code, error) {
+	if v, ok := stringToUint16(s); ok {
+		return newCode(eventType, v), nil
+	}
+
+	nameIndexOnce.Do(initNameIndex)
+	if v, ok := codeNames[eventType][strings.ToUpper(s)]; ok {
+		return newCode(eventType, v), nil
+	}
+
+	return nil, fmt.Errorf("ievio: unknown %v code %q", eventType, s)
+}
+
+var (
+	nameIndexOnce  sync.Once
+	eventTypeNames map[string]EV_TYPE
+	codeNames      map[EV_TYPE]map[string]uint16
+)
+
+// initNameIndex builds the reverse lookup tables from the String methods,
+// which already list every name, aliases separated by '|'.
+func initNameIndex() {
+	eventTypeNames = map[string]EV_TYPE{}
+	for v := EV_TYPE(0); v <= EV_CNT; v++ {
+		for _, name := range namesOf(v.String()) {
+			eventTypeNames[name] = v
+		}
+	}
+
+	codeNames = map[EV_TYPE]map[string]uint16{}
+	for eventType, n := range codeCounts {
+		names := map[string]uint16{}
+		for v := 0; v <= n; v++ {
+			for _, name := range namesOf(makeCode(eventType, uint16(v)).String()) {
+				names[name] = uint16(v)
+			}
+		}
+		codeNames[eventType] = names
+	}
+}
+
+func namesOf(s string) []string {
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		s = s[:i]
 	}
 
+	if s == "UNKNOWN" {
+		return nil
+	}
+
+	return strings.Split(s, "|")
+}
+
+var (
+	codeCacheOnce sync.Once
+	codeCache     [EV_CNT][]Code
+	codeCounts    = map[EV_TYPE]int{
+		EV_SYN:       SYN_CNT,
+		EV_KEY:       KEY_CNT,
+		EV_REL:       REL_CNT,
+		EV_ABS:       ABS_CNT,
+		EV_MSC:       MSC_CNT,
+		EV_SW:        SW_CNT,
+		EV_LED:       LED_CNT,
+		EV_SND:       SND_CNT,
+		EV_REP:       REP_CNT,
+		EV_FF:        FF_CNT,
+		EV_FF_STATUS: FF_STATUS_CNT,
+	}
+)
+
+// newCode returns the Code for v. Codes are immutable, so values inside
+// the range of known codes are shared to keep decoding allocation-free.
+func newCode(eventType EV_TYPE, v uint16) Code {
+	codeCacheOnce.Do(initCodeCache)
+	if int(eventType) < len(codeCache) && int(v) < len(codeCache[eventType]) {
+		return codeCache[eventType][v]
+	}
+
+	return makeCode(eventType, v)
+}
+
+func initCodeCache() {
+	for eventType, n := range codeCounts {
+		codes := make([]Code, n)
+		for i := range codes {
+			codes[i] = makeCode(eventType, uint16(i))
+		}
+		codeCache[eventType] = codes
+	}
+}
+
+func makeCode(eventType EV_TYPE, v uint16) Code {
 	switch eventType {
 	case EV_SYN:
-		return NewSynCode(SYN_CODE(v)), nil
+		return NewSynCode(SYN_CODE(v))
 	case EV_KEY:
-		return NewKeyCode(KEY_CODE(v)), nil
+		return NewKeyCode(KEY_CODE(v))
 	case EV_REL:
-		return NewRelCode(REL_CODE(v)), nil
+		return NewRelCode(REL_CODE(v))
 	case EV_ABS:
-		return NewAbsCode(ABS_CODE(v)), nil
+		return NewAbsCode(ABS_CODE(v))
 	case EV_MSC:
-		return NewMscCode(MSC_CODE(v)), nil
+		return NewMscCode(MSC_CODE(v))
 	case EV_SW:
-		return NewSwCode(SW_CODE(v)), nil
+		return NewSwCode(SW_CODE(v))
 	case EV_LED:
-		return NewLedCode(LED_CODE(v)), nil
+		return NewLedCode(LED_CODE(v))
 	case EV_SND:
-		return NewSndCode(SND_CODE(v)), nil
+		return NewSndCode(SND_CODE(v))
 	case EV_REP:
-		return NewRepCode(REP_CODE(v)), nil
+		return NewRepCode(REP_CODE(v))
 	case EV_FF:
-		return nil, nil // FIXME
-	case EV_PWR:
-		return nil, nil // FIXME
+		return NewFfCode(FF_CODE(v))
 	case EV_FF_STATUS:
-		return nil, nil // FIXME
-	case EV_MAX:
-		return nil, nil // FIXME
-	case EV_CNT:
-		return nil, nil // FIXME
+		return NewFfStatusCode(FF_STATUS_CODE(v))
 	}
 
-	return nil, nil
+	return NewRawCode(v)
 }
 
 type SYN_CODE uint16
@@ -2359,3 +2468,267 @@ func (v SND_CODE) String() string {
 
 	return fmt.Sprintf("UNKNOWN(0x%04x)", uint16(v))
 }
+
+type FF_CODE uint16
+type FfCode struct {
+	v FF_CODE
+}
+
+func NewFfCode(v FF_CODE) *FfCode {
+	return &FfCode{
+		v: v,
+	}
+}
+
+func (v *FfCode) ValueUint16() uint16 {
+	return uint16(v.v)
+}
+
+func (v *FfCode) String() string {
+	return v.v.String()
+}
+
+const (
+	FF_RUMBLE       FF_CODE = 0x50
+	FF_PERIODIC             = 0x51
+	FF_CONSTANT             = 0x52
+	FF_SPRING               = 0x53
+	FF_FRICTION             = 0x54
+	FF_DAMPER               = 0x55
+	FF_INERTIA              = 0x56
+	FF_RAMP                 = 0x57
+	FF_EFFECT_MIN           = FF_RUMBLE
+	FF_EFFECT_MAX           = FF_RAMP
+	FF_SQUARE               = 0x58
+	FF_TRIANGLE             = 0x59
+	FF_SINE                 = 0x5a
+	FF_SAW_UP               = 0x5b
+	FF_SAW_DOWN             = 0x5c
+	FF_CUSTOM               = 0x5d
+	FF_WAVEFORM_MIN         = FF_SQUARE
+	FF_WAVEFORM_MAX         = FF_CUSTOM
+	FF_GAIN                 = 0x60
+	FF_AUTOCENTER           = 0x61
+	FF_MAX_EFFECTS          = FF_GAIN
+	FF_MAX                  = 0x7f
+	FF_CNT                  = (FF_MAX + 1)
+)
+
+func (v FF_CODE) String() string {
+	switch v {
+	case FF_RUMBLE: // | FF_EFFECT_MIN:
+		return fmt.Sprintf("FF_RUMBLE|FF_EFFECT_MIN(0x%04x)", uint16(v))
+	case FF_PERIODIC:
+		return fmt.Sprintf("FF_PERIODIC(0x%04x)", uint16(v))
+	case FF_CONSTANT:
+		return fmt.Sprintf("FF_CONSTANT(0x%04x)", uint16(v))
+	case FF_SPRING:
+		return fmt.Sprintf("FF_SPRING(0x%04x)", uint16(v))
+	case FF_FRICTION:
+		return fmt.Sprintf("FF_FRICTION(0x%04x)", uint16(v))
+	case FF_DAMPER:
+		return fmt.Sprintf("FF_DAMPER(0x%04x)", uint16(v))
+	case FF_INERTIA:
+		return fmt.Sprintf("FF_INERTIA(0x%04x)", uint16(v))
+	case FF_RAMP: // | FF_EFFECT_MAX:
+		return fmt.Sprintf("FF_RAMP|FF_EFFECT_MAX(0x%04x)", uint16(v))
+	case FF_SQUARE: // | FF_WAVEFORM_MIN:
+		return fmt.Sprintf("FF_SQUARE|FF_WAVEFORM_MIN(0x%04x)", uint16(v))
+	case FF_TRIANGLE:
+		return fmt.Sprintf("FF_TRIANGLE(0x%04x)", uint16(v))
+	case FF_SINE:
+		return fmt.Sprintf("FF_SINE(0x%04x)", uint16(v))
+	case FF_SAW_UP:
+		return fmt.Sprintf("FF_SAW_UP(0x%04x)", uint16(v))
+	case FF_SAW_DOWN:
+		return fmt.Sprintf("FF_SAW_DOWN(0x%04x)", uint16(v))
+	case FF_CUSTOM: // | FF_WAVEFORM_MAX:
+		return fmt.Sprintf("FF_CUSTOM|FF_WAVEFORM_MAX(0x%04x)", uint16(v))
+	case FF_GAIN: // | FF_MAX_EFFECTS:
+		return fmt.Sprintf("FF_GAIN|FF_MAX_EFFECTS(0x%04x)", uint16(v))
+	case FF_AUTOCENTER:
+		return fmt.Sprintf("FF_AUTOCENTER(0x%04x)", uint16(v))
+	case FF_MAX:
+		return fmt.Sprintf("FF_MAX(0x%04x)", uint16(v))
+	case FF_CNT:
+		return fmt.Sprintf("FF_CNT(0x%04x)", uint16(v))
+	}
+
+	return fmt.Sprintf("UNKNOWN(0x%04x)", uint16(v))
+}
+
+type FF_STATUS_CODE uint16
+type FfStatusCode struct {
+	v FF_STATUS_CODE
+}
+
+func NewFfStatusCode(v FF_STATUS_CODE) *FfStatusCode {
+	return &FfStatusCode{
+		v: v,
+	}
+}
+
+func (v *FfStatusCode) ValueUint16() uint16 {
+	return uint16(v.v)
+}
+
+func (v *FfStatusCode) String() string {
+	return v.v.String()
+}
+
+const (
+	FF_STATUS_STOPPED FF_STATUS_CODE = 0x00
+	FF_STATUS_PLAYING                = 0x01
+	FF_STATUS_MAX                    = 0x01
+	FF_STATUS_CNT                    = (FF_STATUS_MAX + 1)
+)
+
+func (v FF_STATUS_CODE) String() string {
+	switch v {
+	case FF_STATUS_STOPPED:
+		return fmt.Sprintf("FF_STATUS_STOPPED(0x%04x)", uint16(v))
+	case FF_STATUS_PLAYING: // | FF_STATUS_MAX:
+		return fmt.Sprintf("FF_STATUS_PLAYING|FF_STATUS_MAX(0x%04x)", uint16(v))
+	case FF_STATUS_CNT:
+		return fmt.Sprintf("FF_STATUS_CNT(0x%04x)", uint16(v))
+	}
+
+	return fmt.Sprintf("UNKNOWN(0x%04x)", uint16(v))
+}
+
+// RawCode is the Code of event types that have no code table of their
+// own, such as EV_PWR.
+type RawCode struct {
+	v uint16
+}
+
+func NewRawCode(v uint16) *RawCode {
+	return &RawCode{
+		v: v,
+	}
+}
+
+func (v *RawCode) ValueUint16() uint16 {
+	return v.v
+}
+
+func (v *RawCode) String() string {
+	return fmt.Sprintf("UNKNOWN(0x%04x)", v.v)
+}
+
+type INPUT_PROP uint16
+
+const (
+	INPUT_PROP_POINTER        INPUT_PROP = 0x00
+	INPUT_PROP_DIRECT                    = 0x01
+	INPUT_PROP_BUTTONPAD                 = 0x02
+	INPUT_PROP_SEMI_MT                   = 0x03
+	INPUT_PROP_TOPBUTTONPAD              = 0x04
+	INPUT_PROP_POINTING_STICK            = 0x05
+	INPUT_PROP_ACCELEROMETER             = 0x06
+	INPUT_PROP_MAX                       = 0x1f
+	INPUT_PROP_CNT                       = (INPUT_PROP_MAX + 1)
+)
+
+func (v INPUT_PROP) String() string {
+	switch v {
+	case INPUT_PROP_POINTER:
+		return fmt.Sprintf("INPUT_PROP_POINTER(0x%04x)", uint16(v))
+	case INPUT_PROP_DIRECT:
+		return fmt.Sprintf("INPUT_PROP_DIRECT(0x%04x)", uint16(v))
+	case INPUT_PROP_BUTTONPAD:
+		return fmt.Sprintf("INPUT_PROP_BUTTONPAD(0x%04x)", uint16(v))
+	case INPUT_PROP_SEMI_MT:
+		return fmt.Sprintf("INPUT_PROP_SEMI_MT(0x%04x)", uint16(v))
+	case INPUT_PROP_TOPBUTTONPAD:
+		return fmt.Sprintf("INPUT_PROP_TOPBUTTONPAD(0x%04x)", uint16(v))
+	case INPUT_PROP_POINTING_STICK:
+		return fmt.Sprintf("INPUT_PROP_POINTING_STICK(0x%04x)", uint16(v))
+	case INPUT_PROP_ACCELEROMETER:
+		return fmt.Sprintf("INPUT_PROP_ACCELEROMETER(0x%04x)", uint16(v))
+	case INPUT_PROP_MAX:
+		return fmt.Sprintf("INPUT_PROP_MAX(0x%04x)", uint16(v))
+	case INPUT_PROP_CNT:
+		return fmt.Sprintf("INPUT_PROP_CNT(0x%04x)", uint16(v))
+	}
+
+	return fmt.Sprintf("UNKNOWN(0x%04x)", uint16(v))
+}
+
+type BUS_TYPE uint16
+
+const (
+	BUS_PCI         BUS_TYPE = 0x01
+	BUS_ISAPNP               = 0x02
+	BUS_USB                  = 0x03
+	BUS_HIL                  = 0x04
+	BUS_BLUETOOTH            = 0x05
+	BUS_VIRTUAL              = 0x06
+	BUS_ISA                  = 0x10
+	BUS_I8042                = 0x11
+	BUS_XTKBD                = 0x12
+	BUS_RS232                = 0x13
+	BUS_GAMEPORT             = 0x14
+	BUS_PARPORT              = 0x15
+	BUS_AMIGA                = 0x16
+	BUS_ADB                  = 0x17
+	BUS_I2C                  = 0x18
+	BUS_HOST                 = 0x19
+	BUS_GSC                  = 0x1a
+	BUS_ATARI                = 0x1b
+	BUS_SPI                  = 0x1c
+	BUS_RMI                  = 0x1d
+	BUS_CEC                  = 0x1e
+	BUS_INTEL_ISHTP          = 0x1f
+)
+
+func (v BUS_TYPE) String() string {
+	switch v {
+	case BUS_PCI:
+		return fmt.Sprintf("BUS_PCI(0x%04x)", uint16(v))
+	case BUS_ISAPNP:
+		return fmt.Sprintf("BUS_ISAPNP(0x%04x)", uint16(v))
+	case BUS_USB:
+		return fmt.Sprintf("BUS_USB(0x%04x)", uint16(v))
+	case BUS_HIL:
+		return fmt.Sprintf("BUS_HIL(0x%04x)", uint16(v))
+	case BUS_BLUETOOTH:
+		return fmt.Sprintf("BUS_BLUETOOTH(0x%04x)", uint16(v))
+	case BUS_VIRTUAL:
+		return fmt.Sprintf("BUS_VIRTUAL(0x%04x)", uint16(v))
+	case BUS_ISA:
+		return fmt.Sprintf("BUS_ISA(0x%04x)", uint16(v))
+	case BUS_I8042:
+		return fmt.Sprintf("BUS_I8042(0x%04x)", uint16(v))
+	case BUS_XTKBD:
+		return fmt.Sprintf("BUS_XTKBD(0x%04x)", uint16(v))
+	case BUS_RS232:
+		return fmt.Sprintf("BUS_RS232(0x%04x)", uint16(v))
+	case BUS_GAMEPORT:
+		return fmt.Sprintf("BUS_GAMEPORT(0x%04x)", uint16(v))
+	case BUS_PARPORT:
+		return fmt.Sprintf("BUS_PARPORT(0x%04x)", uint16(v))
+	case BUS_AMIGA:
+		return fmt.Sprintf("BUS_AMIGA(0x%04x)", uint16(v))
+	case BUS_ADB:
+		return fmt.Sprintf("BUS_ADB(0x%04x)", uint16(v))
+	case BUS_I2C:
+		return fmt.Sprintf("BUS_I2C(0x%04x)", uint16(v))
+	case BUS_HOST:
+		return fmt.Sprintf("BUS_HOST(0x%04x)", uint16(v))
+	case BUS_GSC:
+		return fmt.Sprintf("BUS_GSC(0x%04x)", uint16(v))
+	case BUS_ATARI:
+		return fmt.Sprintf("BUS_ATARI(0x%04x)", uint16(v))
+	case BUS_SPI:
+		return fmt.Sprintf("BUS_SPI(0x%04x)", uint16(v))
+	case BUS_RMI:
+		return fmt.Sprintf("BUS_RMI(0x%04x)", uint16(v))
+	case BUS_CEC:
+		return fmt.Sprintf("BUS_CEC(0x%04x)", uint16(v))
+	case BUS_INTEL_ISHTP:
+		return fmt.Sprintf("BUS_INTEL_ISHTP(0x%04x)", uint16(v))
+	}
+
+	return fmt.Sprintf("UNKNOWN(0x%04x)", uint16(v))
+}
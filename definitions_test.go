@@ -0,0 +1,101 @@
+package ievio
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseEventType(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want EV_TYPE
+		ok   bool
+	}{
+		{"EV_KEY", EV_KEY, true},
+		{"ev_key", EV_KEY, true},
+		{"Ev_Ff_Status", EV_FF_STATUS, true},
+		{"1", EV_KEY, true},
+		{"0x3", EV_ABS, true},
+		{"0X1f", EV_MAX, true},
+		{"0o25", EV_FF, true},
+		{"0O4", EV_MSC, true},
+		{"010", EV_TYPE(10), true},
+		{"+2", EV_REL, true},
+		{"65535", EV_TYPE(0xffff), true},
+		{"65536", 0, false},
+		{"-1", 0, false},
+		{"0o8", 0, false},
+		{"EV_NOPE", 0, false},
+		{"KEY_A", 0, false},
+		{"", 0, false},
+	} {
+		got, err := ParseEventType(c.in)
+		if !c.ok {
+			if err == nil {
+				t.Errorf("ParseEventType(%q) = %v, want an error", c.in, got)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("ParseEventType(%q) = %v, %v, want %v", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	for _, c := range []struct {
+		eventType EV_TYPE
+		in        string
+		want      uint16
+		ok        bool
+	}{
+		{EV_KEY, "KEY_A", KEY_A, true},
+		{EV_KEY, "btn_left", BTN_LEFT, true},
+		{EV_KEY, "BTN_A", BTN_SOUTH, true},
+		{EV_KEY, "btn_gamepad", BTN_SOUTH, true},
+		{EV_ABS, "abs_mt_slot", uint16(ABS_MT_SLOT), true},
+		{EV_REL, "REL_WHEEL", REL_WHEEL, true},
+		{EV_KEY, "30", KEY_A, true},
+		{EV_KEY, "0x110", BTN_LEFT, true},
+		{EV_KEY, "0o36", KEY_A, true},
+		{EV_KEY, "010", 10, true},
+		{EV_KEY, "0x10000", 0, false},
+		{EV_KEY, "-30", 0, false},
+		{EV_KEY, "KEY_NOPE", 0, false},
+		{EV_KEY, "REL_X", 0, false},
+		{EV_REL, "KEY_A", 0, false},
+	} {
+		got, err := ParseCode(c.eventType, c.in)
+		if !c.ok {
+			if err == nil {
+				t.Errorf("ParseCode(%v, %q) = %v, want an error", c.eventType, c.in, got)
+			}
+			continue
+		}
+		if err != nil || got.ValueUint16() != c.want {
+			t.Errorf("ParseCode(%v, %q) = %v, %v, want %#x", c.eventType, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestMakeWriteDataValue(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want int32
+	}{
+		{"010", 10},
+		{"0o10", 8},
+		{"-0o10", -8},
+		{"0x10", 16},
+		{"-2147483648", -2147483648},
+	} {
+		input, err := makeWriteData("EV_REL", "REL_X", c.in)
+		if err != nil || input.Value != c.want {
+			t.Errorf("makeWriteData value %q = %v, %v, want %d", c.in, input, err, c.want)
+		}
+	}
+
+	if _, err := makeWriteData("EV_REL", "REL_X", "0o20000000000"); !errors.Is(err, ErrValueRange) {
+		t.Errorf("makeWriteData value 0o20000000000 = %v, want ErrValueRange", err)
+	}
+}
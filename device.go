@@ -0,0 +1,132 @@
+package ievio
+
+import (
+	"context"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+// DefaultBatchSize is the number of input_event records a Device asks
+// the kernel for in a single read.
+const DefaultBatchSize = 64
+
+type Device struct {
+	f     *os.File
+	rc    syscall.RawConn
+	ioctl ioctl.Interface
+	r     *Reader
+	w     *Writer
+
+	grabMu   sync.Mutex
+	grabbed  bool
+	grabStop func() bool
+}
+
+func Open(path string) (*Device, error) {
+	return OpenFile(path, os.O_RDONLY)
+}
+
+// OpenFile opens an input device node with the given os.O_* flags.
+// Character devices are registered with the runtime poller, which is
+// what allows reads to be interrupted by context cancellation.
+func OpenFile(path string, flag int) (*Device, error) {
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := newDevice(f, ioctl.Syscall)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func newDevice(f *os.File, io ioctl.Interface) (*Device, error) {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Device{
+		f:     f,
+		rc:    rc,
+		ioctl: io,
+		r:     NewReader(f, NativeCodec),
+		w:     NewWriter(f, NativeCodec),
+	}, nil
+}
+
+func (d *Device) Path() string {
+	return d.f.Name()
+}
+
+// Close releases any grab held on d before closing it.
+func (d *Device) Close() error {
+	d.Ungrab()
+	err := d.f.Close()
+
+	// Closing the handle drops the grab even if Ungrab failed.
+	d.grabMu.Lock()
+	d.grabbed = false
+	d.grabMu.Unlock()
+	return err
+}
+
+func (d *Device) Codec() Codec {
+	return d.r.Codec()
+}
+
+// SetCodec changes the record layout used for reading and writing, e.g.
+// to replay a capture taken on another platform.
+func (d *Device) SetCodec(c Codec) {
+	d.r.SetCodec(c)
+	d.w.SetCodec(c)
+}
+
+func (d *Device) TimeLayout() TimeLayout {
+	if c, ok := d.Codec().(LayoutCodec); ok {
+		return c.TimeLayout
+	}
+
+	return TimeLayout64
+}
+
+// SetTimeLayout has no effect unless the device uses a LayoutCodec.
+func (d *Device) SetTimeLayout(tl TimeLayout) {
+	if c, ok := d.Codec().(LayoutCodec); ok {
+		c.TimeLayout = tl
+		d.SetCodec(c)
+	}
+}
+
+// watch arranges for any blocked read on d to return once ctx is done.
+// The returned function must be called when the read loop exits.
+func (d *Device) watch(ctx context.Context) func() {
+	return watchDeadline(ctx, d.f)
+}
+
+// watchDeadline sets a read deadline in the past on f once ctx is done.
+// The returned function waits for a callback already under way, which
+// would otherwise set the deadline after the next watch has cleared it.
+func watchDeadline(ctx context.Context, f *os.File) func() {
+	// Regular files do not support deadlines; their reads never block.
+	f.SetReadDeadline(time.Time{})
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(done)
+		f.SetReadDeadline(time.Unix(1, 0))
+	})
+
+	return func() {
+		if !stop() {
+			<-done
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package ievio
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"os"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+// fakeIoctl answers pointer requests by copying canned replies into the
+// argument, or failing with the given error; other pointer requests get
+// ENOTTY. Integer requests are recorded and succeed unless errs says
+// otherwise.
+type fakeIoctl struct {
+	replies map[uintptr][]byte
+	errs    map[uintptr]error
+
+	mu    sync.Mutex
+	calls []intCall
+}
+
+type intCall struct {
+	req uintptr
+	arg uintptr
+}
+
+func newFakeIoctl() *fakeIoctl {
+	return &fakeIoctl{
+		replies: map[uintptr][]byte{},
+		errs:    map[uintptr]error{},
+	}
+}
+
+func (f *fakeIoctl) Ioctl(fd, req uintptr, arg unsafe.Pointer) error {
+	if err, ok := f.errs[req]; ok {
+		return err
+	}
+
+	reply, ok := f.replies[req]
+	if !ok {
+		return syscall.ENOTTY
+	}
+
+	copy(unsafe.Slice((*byte)(arg), len(reply)), reply)
+	return nil
+}
+
+func (f *fakeIoctl) IoctlInt(fd, req, arg uintptr) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, intCall{req, arg})
+	return f.errs[req]
+}
+
+// intCalls returns the integer requests sent so far.
+func (f *fakeIoctl) intCalls() []intCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]intCall(nil), f.calls...)
+}
+
+func (f *fakeIoctl) setString(req func(uintptr) uintptr, s string) {
+	f.replies[req(256)] = append([]byte(s), 0)
+}
+
+// setBits answers the EVIOCGBIT request of eventType (0 for the types
+// themselves) with a bitmap of n bits in native longs.
+func (f *fakeIoctl) setBits(eventType EV_TYPE, n int, set ...int) {
+	buf := make([]byte, longsSize(n))
+	putLongs(buf, set...)
+	f.replies[ioctl.IOC(ioctl.Read, 'E', eviocgbit+uintptr(eventType), uintptr(len(buf)))] = buf
+}
+
+func putLongs(buf []byte, set ...int) {
+	word := bits.UintSize
+	for _, n := range set {
+		i := n / word * (word / 8)
+		if word == 64 {
+			binary.NativeEndian.PutUint64(buf[i:], binary.NativeEndian.Uint64(buf[i:])|1<<(n%word))
+		} else {
+			binary.NativeEndian.PutUint32(buf[i:], binary.NativeEndian.Uint32(buf[i:])|1<<(n%word))
+		}
+	}
+}
+
+func newFakeDevice(t *testing.T, io *fakeIoctl) *Device {
+	f, err := os.CreateTemp(t.TempDir(), "event")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := newDevice(f, io)
+	if err != nil {
+		f.Close()
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// newPipeDevice returns a Device reading from a pipe, with a Writer for
+// its other end.
+func newPipeDevice(t *testing.T) (*Device, *os.File) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := newDevice(r, newFakeIoctl())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		d.Close()
+		w.Close()
+	})
+	return d, w
+}
+
+func writeValues(t *testing.T, f *os.File, values ...int32) {
+	w := NewWriter(f, nil)
+	for _, v := range values {
+		if err := w.WriteEvent(InputEvent{Type: EV_MSC, Code: NewMscCode(MSC_SCAN), Value: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInfo(t *testing.T) {
+	io := newFakeIoctl()
+	io.setString(eviocgname, "Test Keyboard")
+	io.setString(eviocgphys, "usb-0000:00:14.0-1/input0")
+	io.errs[eviocguniq(256)] = syscall.ENOENT
+
+	id := InputID{BusType: BUS_USB, Vendor: 0x046d, Product: 0xc31c, Version: 0x0110}
+	io.replies[eviocgid] = unsafe.Slice((*byte)(unsafe.Pointer(&id)), unsafe.Sizeof(id))
+	io.replies[eviocgversion] = binary.NativeEndian.AppendUint32(nil, 0x010001)
+
+	d := newFakeDevice(t, io)
+	info, err := d.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := DeviceInfo{
+		Name:          "Test Keyboard",
+		Phys:          "usb-0000:00:14.0-1/input0",
+		Uniq:          "",
+		ID:            id,
+		DriverVersion: 0x010001,
+	}
+	if *info != want {
+		t.Errorf("Info() = %v, want %v", info, &want)
+	}
+
+	if got, err := d.ID(); err != nil || got != id {
+		t.Errorf("ID() = %v, %v, want %v", got, err, id)
+	}
+}
+
+func TestInfoError(t *testing.T) {
+	io := newFakeIoctl()
+	io.setString(eviocgname, "Test Keyboard")
+	io.errs[eviocgphys(256)] = syscall.ENODEV
+
+	d := newFakeDevice(t, io)
+	if _, err := d.Info(); err != syscall.ENODEV {
+		t.Errorf("Info() error = %v, want ENODEV", err)
+	}
+
+	// ENOENT only means "no such string"; other errors are not hidden.
+	if _, err := d.Uniq(); err != syscall.ENOTTY {
+		t.Errorf("Uniq() error = %v, want ENOTTY", err)
+	}
+}
+
+func TestBitsetFromLongs(t *testing.T) {
+	set := []int{0, 1, 31, 32, 63, 64, 65, 0x130, 0x2ff}
+	buf := make([]byte, longsSize(KEY_CNT))
+	putLongs(buf, set...)
+
+	b := bitsetFromLongs(buf)
+	if got := b.Bits(); !reflect.DeepEqual(got, toUint16s(set)) {
+		t.Errorf("Bits() = %v, want %v", got, set)
+	}
+	for _, n := range []uint16{2, 30, 33, 62, 66, 0x12f, 0x131} {
+		if b.Has(n) {
+			t.Errorf("Has(%d) = true", n)
+		}
+	}
+}
+
+func toUint16s(v []int) []uint16 {
+	u := make([]uint16, len(v))
+	for i, n := range v {
+		u[i] = uint16(n)
+	}
+
+	return u
+}
+
+func TestCapabilities(t *testing.T) {
+	io := newFakeIoctl()
+	io.setBits(EV_SYN, EV_CNT, int(EV_SYN), int(EV_KEY), int(EV_REL))
+	io.setBits(EV_KEY, KEY_CNT, BTN_LEFT, BTN_RIGHT)
+	io.setBits(EV_REL, REL_CNT, int(REL_X), REL_Y, REL_WHEEL)
+
+	d := newFakeDevice(t, io)
+	caps, err := d.Capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := caps.EventTypes(); !reflect.DeepEqual(got, []EV_TYPE{EV_SYN, EV_KEY, EV_REL}) {
+		t.Errorf("EventTypes() = %v", got)
+	}
+	if got := caps.Keys(); !reflect.DeepEqual(got, []KEY_CODE{BTN_LEFT, BTN_RIGHT}) {
+		t.Errorf("Keys() = %v", got)
+	}
+	if got := caps.Rels(); !reflect.DeepEqual(got, []REL_CODE{REL_X, REL_Y, REL_WHEEL}) {
+		t.Errorf("Rels() = %v", got)
+	}
+	if !caps.Supports(EV_REL, NewRelCode(REL_WHEEL)) || caps.Supports(EV_ABS, nil) || caps.Supports(EV_KEY, NewKeyCode(KEY_A)) {
+		t.Error("Supports() disagrees with the bitmaps")
+	}
+}
+
+func TestAbsInfoCode(t *testing.T) {
+	io := newFakeIoctl()
+	want := AbsInfo{Minimum: -32768, Maximum: 32767, Flat: 128}
+	io.replies[eviocgabs(ABS_MAX)] = unsafe.Slice((*byte)(unsafe.Pointer(&want)), unsafe.Sizeof(want))
+
+	d := newFakeDevice(t, io)
+	if got, err := d.AbsInfo(ABS_MAX); err != nil || got != want {
+		t.Errorf("AbsInfo(ABS_MAX) = %+v, %v, want %+v", got, err, want)
+	}
+	if _, err := d.AbsInfo(ABS_MAX + 1); err != ErrAbsCode {
+		t.Errorf("AbsInfo(ABS_MAX+1) error = %v, want ErrAbsCode", err)
+	}
+	if err := d.SetAbsInfo(ABS_MAX+1, want); err != ErrAbsCode {
+		t.Errorf("SetAbsInfo(ABS_MAX+1) error = %v, want ErrAbsCode", err)
+	}
+}
@@ -0,0 +1,248 @@
+package ievio
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"runtime"
+	"unsafe"
+
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+// ffEffectSize is sizeof(struct ff_effect): a 16-byte header followed by
+// a union whose largest member, ff_periodic_effect, ends with a pointer.
+const ffEffectSize = 40 + bits.UintSize/8
+
+var (
+	eviocsff      = ioctl.IOW('E', 0x80, ffEffectSize)
+	eviocrmff     = ioctl.IOW('E', 0x81, unsafe.Sizeof(int32(0)))
+	eviocgeffects = ioctl.IOR('E', 0x84, unsafe.Sizeof(int32(0)))
+)
+
+var ErrFFEffectType = errors.New("ievio: unsupported force-feedback effect type")
+
+// FFEnvelope mirrors struct ff_envelope. Lengths are in milliseconds.
+type FFEnvelope struct {
+	AttackLength uint16
+	AttackLevel  uint16
+	FadeLength   uint16
+	FadeLevel    uint16
+}
+
+// FFTrigger mirrors struct ff_trigger. Interval is in milliseconds.
+type FFTrigger struct {
+	Button   uint16
+	Interval uint16
+}
+
+// FFReplay mirrors struct ff_replay. Both fields are in milliseconds.
+type FFReplay struct {
+	Length uint16
+	Delay  uint16
+}
+
+type FFConstant struct {
+	Level    int16
+	Envelope FFEnvelope
+}
+
+type FFRamp struct {
+	StartLevel int16
+	EndLevel   int16
+	Envelope   FFEnvelope
+}
+
+// FFPeriodic mirrors struct ff_periodic_effect. CustomData is only used
+// with the FF_CUSTOM waveform.
+type FFPeriodic struct {
+	Waveform   FF_CODE
+	Period     uint16
+	Magnitude  int16
+	Offset     int16
+	Phase      uint16
+	Envelope   FFEnvelope
+	CustomData []int16
+}
+
+// FFCondition mirrors struct ff_condition_effect, used by FF_SPRING,
+// FF_FRICTION, FF_DAMPER and FF_INERTIA.
+type FFCondition struct {
+	RightSaturation uint16
+	LeftSaturation  uint16
+	RightCoeff      int16
+	LeftCoeff       int16
+	Deadband        uint16
+	Center          int16
+}
+
+type FFRumble struct {
+	StrongMagnitude uint16
+	WeakMagnitude   uint16
+}
+
+// FFEffect mirrors struct ff_effect. Only the member matching Type is
+// used. ID is -1 for an effect that has not been uploaded yet.
+type FFEffect struct {
+	Type      FF_CODE
+	ID        int16
+	Direction uint16
+	Trigger   FFTrigger
+	Replay    FFReplay
+
+	Constant  FFConstant
+	Ramp      FFRamp
+	Periodic  FFPeriodic
+	Condition [2]FFCondition
+	Rumble    FFRumble
+}
+
+func NewRumbleEffect(strong, weak uint16, length uint16) *FFEffect {
+	return &FFEffect{
+		Type:   FF_RUMBLE,
+		ID:     -1,
+		Replay: FFReplay{Length: length},
+		Rumble: FFRumble{StrongMagnitude: strong, WeakMagnitude: weak},
+	}
+}
+
+func NewPeriodicEffect(waveform FF_CODE, period uint16, magnitude int16, length uint16) *FFEffect {
+	return &FFEffect{
+		Type:     FF_PERIODIC,
+		ID:       -1,
+		Replay:   FFReplay{Length: length},
+		Periodic: FFPeriodic{Waveform: waveform, Period: period, Magnitude: magnitude},
+	}
+}
+
+func NewConstantEffect(level int16, length uint16) *FFEffect {
+	return &FFEffect{
+		Type:     FF_CONSTANT,
+		ID:       -1,
+		Replay:   FFReplay{Length: length},
+		Constant: FFConstant{Level: level},
+	}
+}
+
+// encode lays e out as struct ff_effect in buf. Custom samples are
+// referenced by address, so encode pins them with pin; the caller unpins
+// once the kernel has copied them.
+func (e *FFEffect) encode(buf []byte, pin *runtime.Pinner) error {
+	o := binary.NativeEndian
+	o.PutUint16(buf[0:], uint16(e.Type))
+	o.PutUint16(buf[2:], uint16(e.ID))
+	o.PutUint16(buf[4:], e.Direction)
+	o.PutUint16(buf[6:], e.Trigger.Button)
+	o.PutUint16(buf[8:], e.Trigger.Interval)
+	o.PutUint16(buf[10:], e.Replay.Length)
+	o.PutUint16(buf[12:], e.Replay.Delay)
+
+	u := buf[16:]
+	switch e.Type {
+	case FF_CONSTANT:
+		o.PutUint16(u[0:], uint16(e.Constant.Level))
+		putEnvelope(u[2:], &e.Constant.Envelope)
+	case FF_RAMP:
+		o.PutUint16(u[0:], uint16(e.Ramp.StartLevel))
+		o.PutUint16(u[2:], uint16(e.Ramp.EndLevel))
+		putEnvelope(u[4:], &e.Ramp.Envelope)
+	case FF_PERIODIC:
+		p := &e.Periodic
+		o.PutUint16(u[0:], uint16(p.Waveform))
+		o.PutUint16(u[2:], p.Period)
+		o.PutUint16(u[4:], uint16(p.Magnitude))
+		o.PutUint16(u[6:], uint16(p.Offset))
+		o.PutUint16(u[8:], p.Phase)
+		putEnvelope(u[10:], &p.Envelope)
+		if p.Waveform == FF_CUSTOM && len(p.CustomData) > 0 {
+			pin.Pin(&p.CustomData[0])
+			o.PutUint32(u[20:], uint32(len(p.CustomData)))
+			putPointer(u[24:], uintptr(unsafe.Pointer(&p.CustomData[0])))
+		}
+	case FF_SPRING, FF_FRICTION, FF_DAMPER, FF_INERTIA:
+		for i, c := range e.Condition {
+			v := u[i*12:]
+			o.PutUint16(v[0:], c.RightSaturation)
+			o.PutUint16(v[2:], c.LeftSaturation)
+			o.PutUint16(v[4:], uint16(c.RightCoeff))
+			o.PutUint16(v[6:], uint16(c.LeftCoeff))
+			o.PutUint16(v[8:], c.Deadband)
+			o.PutUint16(v[10:], uint16(c.Center))
+		}
+	case FF_RUMBLE:
+		o.PutUint16(u[0:], e.Rumble.StrongMagnitude)
+		o.PutUint16(u[2:], e.Rumble.WeakMagnitude)
+	default:
+		return ErrFFEffectType
+	}
+
+	return nil
+}
+
+func putEnvelope(buf []byte, v *FFEnvelope) {
+	o := binary.NativeEndian
+	o.PutUint16(buf[0:], v.AttackLength)
+	o.PutUint16(buf[2:], v.AttackLevel)
+	o.PutUint16(buf[4:], v.FadeLength)
+	o.PutUint16(buf[6:], v.FadeLevel)
+}
+
+func putPointer(buf []byte, p uintptr) {
+	if bits.UintSize == 64 {
+		binary.NativeEndian.PutUint64(buf, uint64(p))
+	} else {
+		binary.NativeEndian.PutUint32(buf, uint32(p))
+	}
+}
+
+// UploadEffect uploads e to the device, or updates it if e.ID is not -1,
+// and stores the id assigned by the kernel in e.ID.
+func (d *Device) UploadEffect(e *FFEffect) error {
+	var pin runtime.Pinner
+	defer pin.Unpin()
+
+	buf := make([]byte, ffEffectSize)
+	if err := e.encode(buf, &pin); err != nil {
+		return err
+	}
+	if err := d.ioctlPtr(eviocsff, unsafe.Pointer(&buf[0])); err != nil {
+		return err
+	}
+
+	e.ID = int16(binary.NativeEndian.Uint16(buf[2:]))
+	return nil
+}
+
+// EraseEffect removes an uploaded effect from the device.
+func (d *Device) EraseEffect(id int16) error {
+	return d.ioctlInt(eviocrmff, uintptr(id))
+}
+
+// EffectsCapacity returns the number of effects the device can play at
+// the same time.
+func (d *Device) EffectsCapacity() (int, error) {
+	var n int32
+	err := d.ioctlPtr(eviocgeffects, unsafe.Pointer(&n))
+	return int(n), err
+}
+
+// PlayEffect starts an uploaded effect and repeats it count times. Like
+// every EV_FF write, it requires the device to be opened for writing.
+func (d *Device) PlayEffect(id int16, count int32) error {
+	return d.WriteEvent(InputEvent{Type: EV_FF, Code: newCode(EV_FF, uint16(id)), Value: count})
+}
+
+func (d *Device) StopEffect(id int16) error {
+	return d.PlayEffect(id, 0)
+}
+
+// SetFFGain sets the overall strength of all effects, 0xffff being full.
+func (d *Device) SetFFGain(gain uint16) error {
+	return d.WriteEvent(InputEvent{Type: EV_FF, Code: NewFfCode(FF_GAIN), Value: int32(gain)})
+}
+
+// SetFFAutocenter sets the strength of the autocenter spring, 0 to
+// disable it.
+func (d *Device) SetFFAutocenter(strength uint16) error {
+	return d.WriteEvent(InputEvent{Type: EV_FF, Code: NewFfCode(FF_AUTOCENTER), Value: int32(strength)})
+}
@@ -0,0 +1,100 @@
+package ievio
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestFFEffectSize(t *testing.T) {
+	want := 44
+	if bits.UintSize == 64 {
+		want = 48
+	}
+	if ffEffectSize != want {
+		t.Errorf("ffEffectSize = %d, want %d", ffEffectSize, want)
+	}
+}
+
+// TestFFEffectEncode checks the offsets of struct ff_effect: the union at
+// 16, and within it custom_len at 20, custom_data at 24 and the second
+// ff_condition_effect at 12.
+func TestFFEffectEncode(t *testing.T) {
+	o := binary.NativeEndian
+	var pin runtime.Pinner
+	defer pin.Unpin()
+
+	rumble := NewRumbleEffect(0x1234, 0x5678, 500)
+	buf := make([]byte, ffEffectSize)
+	if err := rumble.encode(buf, &pin); err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Uint16(buf[0:]); got != uint16(FF_RUMBLE) {
+		t.Errorf("type = %#x, want FF_RUMBLE", got)
+	}
+	if got := int16(o.Uint16(buf[2:])); got != -1 {
+		t.Errorf("id = %d, want -1", got)
+	}
+	if got := o.Uint16(buf[10:]); got != 500 {
+		t.Errorf("replay.length = %d, want 500", got)
+	}
+	if strong, weak := o.Uint16(buf[16:]), o.Uint16(buf[18:]); strong != 0x1234 || weak != 0x5678 {
+		t.Errorf("rumble = %#x %#x, want 0x1234 0x5678", strong, weak)
+	}
+
+	samples := []int16{1, -1, 2}
+	custom := &FFEffect{Type: FF_PERIODIC, Periodic: FFPeriodic{Waveform: FF_CUSTOM, CustomData: samples}}
+	buf = make([]byte, ffEffectSize)
+	if err := custom.encode(buf, &pin); err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Uint16(buf[16:]); got != uint16(FF_CUSTOM) {
+		t.Errorf("periodic.waveform = %#x, want FF_CUSTOM", got)
+	}
+	if got := o.Uint32(buf[16+20:]); got != uint32(len(samples)) {
+		t.Errorf("periodic.custom_len = %d, want %d", got, len(samples))
+	}
+	var ptr uintptr
+	if bits.UintSize == 64 {
+		ptr = uintptr(o.Uint64(buf[16+24:]))
+	} else {
+		ptr = uintptr(o.Uint32(buf[16+24:]))
+	}
+	if want := uintptr(unsafe.Pointer(&samples[0])); ptr != want {
+		t.Errorf("periodic.custom_data = %#x, want %#x", ptr, want)
+	}
+
+	spring := &FFEffect{Type: FF_SPRING, Condition: [2]FFCondition{{Center: 1}, {RightSaturation: 0xabcd, Center: -2}}}
+	buf = make([]byte, ffEffectSize)
+	if err := spring.encode(buf, &pin); err != nil {
+		t.Fatal(err)
+	}
+	if got := int16(o.Uint16(buf[16+10:])); got != 1 {
+		t.Errorf("condition[0].center = %d, want 1", got)
+	}
+	if got := o.Uint16(buf[16+12:]); got != 0xabcd {
+		t.Errorf("condition[1].right_saturation = %#x, want 0xabcd", got)
+	}
+	if got := int16(o.Uint16(buf[16+22:])); got != -2 {
+		t.Errorf("condition[1].center = %d, want -2", got)
+	}
+}
+
+func TestUploadEffect(t *testing.T) {
+	io := newFakeIoctl()
+	reply := make([]byte, 4)
+	binary.NativeEndian.PutUint16(reply[0:], uint16(FF_RUMBLE))
+	binary.NativeEndian.PutUint16(reply[2:], 3)
+	io.replies[eviocsff] = reply
+
+	d := newFakeDevice(t, io)
+	e := NewRumbleEffect(0xffff, 0, 100)
+	if err := d.UploadEffect(e); err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 3 {
+		t.Errorf("ID after UploadEffect = %d, want 3", e.ID)
+	}
+}
@@ -0,0 +1,210 @@
+package ievio
+
+import (
+	"context"
+	"io"
+)
+
+// FrameBuilder collects the events of a single input frame so that they
+// reach the device, terminated by SYN_REPORT, in one write.
+type FrameBuilder struct {
+	w      *Writer
+	events []InputEvent
+}
+
+func (w *Writer) BeginFrame() *FrameBuilder {
+	return &FrameBuilder{
+		w: w,
+	}
+}
+
+func (d *Device) BeginFrame() *FrameBuilder {
+	return d.w.BeginFrame()
+}
+
+func (b *FrameBuilder) Add(eventType EV_TYPE, code Code, value int32) *FrameBuilder {
+	b.events = append(b.events, InputEvent{Type: eventType, Code: code, Value: value})
+	return b
+}
+
+func (b *FrameBuilder) Len() int {
+	return len(b.events)
+}
+
+func (b *FrameBuilder) Reset() {
+	b.events = b.events[:0]
+}
+
+// Commit writes the collected events followed by SYN_REPORT and resets
+// b so that it can be reused for the next frame.
+func (b *FrameBuilder) Commit() error {
+	if len(b.events) == 0 || !isSynReport(&b.events[len(b.events)-1]) {
+		b.events = append(b.events, synReport())
+	}
+
+	err := b.w.WriteEvents(b.events)
+	b.Reset()
+	return err
+}
+
+// WriteFrame writes events as a single frame, appending SYN_REPORT unless
+// the last event already is one.
+func (w *Writer) WriteFrame(events []InputEvent) error {
+	if len(events) > 0 && isSynReport(&events[len(events)-1]) {
+		return w.WriteEvents(events)
+	}
+
+	frame := make([]InputEvent, len(events), len(events)+1)
+	copy(frame, events)
+	return w.WriteEvents(append(frame, synReport()))
+}
+
+func (d *Device) WriteFrame(events []InputEvent) error {
+	return d.w.WriteFrame(events)
+}
+
+// Frame is the set of events the kernel reported as a single atomic
+// change, such as the X and Y deltas of one mouse movement. Time is that
+// of the terminating SYN_REPORT, which is not part of Events.
+type Frame struct {
+	Time   Timeval
+	Events []InputEvent
+}
+
+// EventSource is anything events can be read from one at a time, such as
+// a Device. ReadEvent returns io.EOF at the end of the stream.
+type EventSource interface {
+	ReadEvent(ctx context.Context) (*InputEvent, error)
+}
+
+// EventSourceFunc adapts a function to EventSource.
+type EventSourceFunc func(ctx context.Context) (*InputEvent, error)
+
+func (f EventSourceFunc) ReadEvent(ctx context.Context) (*InputEvent, error) {
+	return f(ctx)
+}
+
+// ReaderSource reads from r, which cannot be interrupted: ctx is only
+// checked between events.
+func ReaderSource(r *Reader) EventSource {
+	return EventSourceFunc(func(ctx context.Context) (*InputEvent, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return r.ReadEvent()
+	})
+}
+
+// ChanSource reads the events channel returned by Device.Stream.
+func ChanSource(events <-chan InputEvent) EventSource {
+	return EventSourceFunc(func(ctx context.Context) (*InputEvent, error) {
+		select {
+		case input, ok := <-events:
+			if !ok {
+				return nil, io.EOF
+			}
+			return &input, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
+
+// FrameReader groups events into frames. When the kernel signals with
+// SYN_DROPPED that its buffer overflowed, the incomplete frame and every
+// event up to the next SYN_REPORT are discarded.
+type FrameReader struct {
+	src     EventSource
+	events  []InputEvent
+	dropped bool
+}
+
+// NewFrameReader returns a FrameReader reading from src. A nil src is
+// allowed if events are only passed in with Feed, e.g. those of one device
+// of a Mux.
+func NewFrameReader(src EventSource) *FrameReader {
+	return &FrameReader{
+		src: src,
+	}
+}
+
+// ReadFrame returns the next complete frame, or io.EOF at the end of the
+// stream, in which case any incomplete frame is discarded. After other
+// errors the incomplete frame is kept for the next call.
+func (r *FrameReader) ReadFrame(ctx context.Context) (*Frame, error) {
+	for {
+		input, err := r.src.ReadEvent(ctx)
+		if err == io.EOF {
+			r.events, r.dropped = nil, false
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if frame := r.Feed(*input); frame != nil {
+			return frame, nil
+		}
+	}
+}
+
+// Feed adds input to the frame being assembled and returns that frame if
+// input completes it, or nil.
+func (r *FrameReader) Feed(input InputEvent) *Frame {
+	if input.Type == EV_SYN && input.Code != nil {
+		switch input.Code.ValueUint16() {
+		case uint16(SYN_REPORT):
+			if r.dropped {
+				r.dropped = false
+				return nil
+			}
+
+			frame := &Frame{Time: input.Time, Events: r.events}
+			r.events = nil
+			return frame
+		case uint16(SYN_DROPPED):
+			r.dropped = true
+			r.events = nil
+			return nil
+		}
+	}
+
+	if !r.dropped {
+		r.events = append(r.events, input)
+	}
+	return nil
+}
+
+// Frames calls handler for every frame read from d until the end of the
+// stream, an error, or ctx is done, in which case ctx.Err() is returned.
+func (d *Device) Frames(ctx context.Context, handler func(*Frame)) error {
+	stop := d.watch(ctx)
+	defer stop()
+	r := NewFrameReader(EventSourceFunc(func(ctx context.Context) (*InputEvent, error) {
+		input := InputEvent{}
+		if err := d.readEvent(ctx, &input); err != nil {
+			return nil, err
+		}
+		return &input, nil
+	}))
+
+	for {
+		frame, err := r.ReadFrame(ctx)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		handler(frame)
+	}
+}
+
+func synReport() InputEvent {
+	return InputEvent{Type: EV_SYN, Code: NewSynCode(SYN_REPORT), Value: 0}
+}
+
+func isSynReport(input *InputEvent) bool {
+	return input.Type == EV_SYN && input.Code != nil && input.Code.ValueUint16() == uint16(SYN_REPORT)
+}
@@ -0,0 +1,137 @@
+package ievio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var _ EventSource = (*Device)(nil)
+
+func rel(code REL_CODE, value int32) InputEvent {
+	return InputEvent{Time: Timeval{Sec: 1}, Type: EV_REL, Code: NewRelCode(code), Value: value}
+}
+
+func syn(code SYN_CODE, usec int64) InputEvent {
+	return InputEvent{Time: Timeval{Sec: 1, Usec: usec}, Type: EV_SYN, Code: NewSynCode(code)}
+}
+
+// frameStream holds two frames around one that the kernel partly dropped,
+// and an incomplete one at the end.
+var frameStream = []InputEvent{
+	rel(REL_X, 1), rel(REL_Y, -1), syn(SYN_REPORT, 10),
+	rel(REL_X, 9), syn(SYN_DROPPED, 20), rel(REL_Y, 9), syn(SYN_REPORT, 30),
+	rel(REL_WHEEL, 1), syn(SYN_REPORT, 40),
+	rel(REL_X, 7),
+}
+
+type wantFrame struct {
+	usec   int64
+	values []int32
+}
+
+func frameStreamBytes(t *testing.T) []byte {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, nil).WriteEvents(frameStream); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func checkFrames(t *testing.T, r *FrameReader) {
+	ctx := context.Background()
+	for _, want := range []wantFrame{{10, []int32{1, -1}}, {40, []int32{1}}} {
+		frame, err := r.ReadFrame(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var values []int32
+		for _, input := range frame.Events {
+			values = append(values, input.Value)
+		}
+		if frame.Time.Usec != want.usec || !reflect.DeepEqual(values, want.values) {
+			t.Errorf("ReadFrame() = %v %v, want %v %v", frame.Time, values, want.usec, want.values)
+		}
+	}
+
+	if frame, err := r.ReadFrame(ctx); err != io.EOF {
+		t.Errorf("ReadFrame() at the end = %v, %v, want io.EOF", frame, err)
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	checkFrames(t, NewFrameReader(ReaderSource(NewReader(bytes.NewReader(frameStreamBytes(t)), nil))))
+}
+
+func TestFrameReaderDevice(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(frameStreamBytes(t)); err != nil {
+		t.Fatal(err)
+	}
+	f.Seek(0, io.SeekStart)
+
+	d, err := newDevice(f, newFakeIoctl())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	checkFrames(t, NewFrameReader(d))
+}
+
+func TestFrameReaderChan(t *testing.T) {
+	events := make(chan InputEvent, len(frameStream))
+	for _, input := range frameStream {
+		events <- input
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewFrameReader(ChanSource(events))
+	for i := 0; i < 2; i++ {
+		if _, err := r.ReadFrame(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The last frame is incomplete and the channel still open.
+	cancel()
+	if _, err := r.ReadFrame(ctx); err != context.Canceled {
+		t.Errorf("ReadFrame() = %v, want context.Canceled", err)
+	}
+
+	close(events)
+	if _, err := r.ReadFrame(context.Background()); err != io.EOF {
+		t.Errorf("ReadFrame() on a closed channel = %v, want io.EOF", err)
+	}
+
+	// The incomplete frame ended with the stream.
+	if frame := r.Feed(syn(SYN_REPORT, 50)); frame == nil || len(frame.Events) != 0 {
+		t.Errorf("frame after io.EOF = %v, want an empty frame", frame)
+	}
+}
+
+// TestFrameReaderFeed assembles the frames of two interleaved devices, as
+// read from a Mux.
+func TestFrameReaderFeed(t *testing.T) {
+	a, b := NewFrameReader(nil), NewFrameReader(nil)
+	if a.Feed(rel(REL_X, 1)) != nil || b.Feed(rel(REL_X, 2)) != nil || a.Feed(rel(REL_Y, 3)) != nil {
+		t.Fatal("Feed() returned a frame before SYN_REPORT")
+	}
+
+	fb := b.Feed(syn(SYN_REPORT, 0))
+	fa := a.Feed(syn(SYN_REPORT, 0))
+	if fa == nil || len(fa.Events) != 2 || fa.Events[1].Value != 3 {
+		t.Errorf("frame of a = %v", fa)
+	}
+	if fb == nil || len(fb.Events) != 1 || fb.Events[0].Value != 2 {
+		t.Errorf("frame of b = %v", fb)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/niumlaque/ievio
+
+go 1.21
@@ -0,0 +1,72 @@
+package ievio
+
+import (
+	"context"
+	"unsafe"
+
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+var eviocgrab = ioctl.IOW('E', 0x90, unsafe.Sizeof(int32(0)))
+
+// Grab gives d exclusive access to the device: no other handle, including
+// the display server, receives its events until Ungrab or Close. The
+// kernel also drops the grab when the process exits, even on a crash.
+func (d *Device) Grab() error {
+	d.grabMu.Lock()
+	defer d.grabMu.Unlock()
+	if d.grabbed {
+		return nil
+	}
+
+	if err := d.ioctlInt(eviocgrab, 1); err != nil {
+		return err
+	}
+
+	d.grabbed = true
+	return nil
+}
+
+// GrabContext is like Grab, but also releases the grab once ctx is done.
+func (d *Device) GrabContext(ctx context.Context) error {
+	if err := d.Grab(); err != nil {
+		return err
+	}
+
+	d.grabMu.Lock()
+	defer d.grabMu.Unlock()
+	if d.grabStop != nil {
+		d.grabStop()
+	}
+	d.grabStop = context.AfterFunc(ctx, func() {
+		d.Ungrab()
+	})
+
+	return nil
+}
+
+func (d *Device) Ungrab() error {
+	d.grabMu.Lock()
+	defer d.grabMu.Unlock()
+	if d.grabStop != nil {
+		d.grabStop()
+		d.grabStop = nil
+	}
+
+	if !d.grabbed {
+		return nil
+	}
+
+	if err := d.ioctlInt(eviocgrab, 0); err != nil {
+		return err
+	}
+
+	d.grabbed = false
+	return nil
+}
+
+func (d *Device) Grabbed() bool {
+	d.grabMu.Lock()
+	defer d.grabMu.Unlock()
+	return d.grabbed
+}
@@ -0,0 +1,97 @@
+package ievio
+
+import (
+	"context"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestGrab(t *testing.T) {
+	io := newFakeIoctl()
+	d := newFakeDevice(t, io)
+
+	if err := d.Grab(); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Grabbed() {
+		t.Error("Grabbed() = false after Grab")
+	}
+
+	// Grabbing twice is a no-op.
+	if err := d.Grab(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Ungrab(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Grabbed() {
+		t.Error("Grabbed() = true after Ungrab")
+	}
+
+	want := []intCall{{eviocgrab, 1}, {eviocgrab, 0}}
+	if got := io.intCalls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ioctls = %x, want %x", got, want)
+	}
+}
+
+func TestGrabError(t *testing.T) {
+	io := newFakeIoctl()
+	io.errs[eviocgrab] = syscall.EBUSY
+	d := newFakeDevice(t, io)
+
+	if err := d.Grab(); err != syscall.EBUSY {
+		t.Errorf("Grab() = %v, want EBUSY", err)
+	}
+	if d.Grabbed() {
+		t.Error("Grabbed() = true after a failed Grab")
+	}
+}
+
+func TestCloseUngrabs(t *testing.T) {
+	io := newFakeIoctl()
+	d := newFakeDevice(t, io)
+	if err := d.Grab(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Grabbed() {
+		t.Error("Grabbed() = true after Close")
+	}
+
+	want := []intCall{{eviocgrab, 1}, {eviocgrab, 0}}
+	if got := io.intCalls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ioctls = %x, want %x", got, want)
+	}
+}
+
+func TestGrabContext(t *testing.T) {
+	io := newFakeIoctl()
+	d := newFakeDevice(t, io)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := d.GrabContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Grabbed() {
+		t.Error("Grabbed() = false after GrabContext")
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for d.Grabbed() {
+		if time.Now().After(deadline) {
+			t.Fatal("grab still held after cancel")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	want := []intCall{{eviocgrab, 1}, {eviocgrab, 0}}
+	if got := io.intCalls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ioctls = %x, want %x", got, want)
+	}
+}
@@ -2,16 +2,60 @@ package ievio
 
 import (
 	"fmt"
-	"syscall"
+	"time"
 )
 
+// Timeval is the timestamp of an input_event. Unlike syscall.Timeval its
+// fields have the same width on every architecture; the on-wire layout
+// is only dealt with when events are encoded and decoded.
+type Timeval struct {
+	Sec  int64
+	Usec int64
+}
+
+func NewTimeval(t time.Time) Timeval {
+	return Timeval{
+		Sec:  t.Unix(),
+		Usec: int64(t.Nanosecond() / 1000),
+	}
+}
+
+func (v Timeval) Time() time.Time {
+	return time.Unix(v.Sec, v.Usec*1000)
+}
+
+// InputID mirrors struct input_id.
+type InputID struct {
+	BusType BUS_TYPE
+	Vendor  uint16
+	Product uint16
+	Version uint16
+}
+
+func (v InputID) String() string {
+	return fmt.Sprintf("%v %04x:%04x v%04x", v.BusType, v.Vendor, v.Product, v.Version)
+}
+
 type InputEvent struct {
-	Time  syscall.Timeval
+	Time  Timeval
 	Type  EV_TYPE
 	Code  Code
 	Value int32
 }
 
+func NewInputEvent(t time.Time, eventType EV_TYPE, code Code, value int32) *InputEvent {
+	return &InputEvent{
+		Time:  NewTimeval(t),
+		Type:  eventType,
+		Code:  code,
+		Value: value,
+	}
+}
+
+func (v *InputEvent) Timestamp() time.Time {
+	return v.Time.Time()
+}
+
 func (v *InputEvent) String() string {
 	return fmt.Sprintf("%v, %v, %v, %v", v.Time, v.Type, v.Code, v.Value)
 }
@@ -1,7 +0,0 @@
-// +build 386 arm
-
-package ievio
-
-const (
-	InputEventSize = 16
-)
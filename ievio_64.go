@@ -1,7 +0,0 @@
-// +build amd64
-
-package ievio
-
-const (
-	InputEventSize = 24
-)
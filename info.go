@@ -0,0 +1,70 @@
+package ievio
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// DeviceInfo is the identity of an input device as reported by evdev.
+type DeviceInfo struct {
+	Name          string
+	Phys          string
+	Uniq          string
+	ID            InputID
+	DriverVersion uint32
+}
+
+func (v *DeviceInfo) String() string {
+	return fmt.Sprintf("%q, %v, %q, %q", v.Name, v.ID, v.Phys, v.Uniq)
+}
+
+func (d *Device) Info() (*DeviceInfo, error) {
+	var info DeviceInfo
+	var err error
+	if info.Name, err = d.Name(); err != nil {
+		return nil, err
+	}
+
+	if info.Phys, err = d.Phys(); err != nil {
+		return nil, err
+	}
+
+	if info.Uniq, err = d.Uniq(); err != nil {
+		return nil, err
+	}
+
+	if info.ID, err = d.ID(); err != nil {
+		return nil, err
+	}
+
+	if info.DriverVersion, err = d.DriverVersion(); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func (d *Device) Name() (string, error) {
+	return d.ioctlString(eviocgname)
+}
+
+func (d *Device) Phys() (string, error) {
+	return d.ioctlString(eviocgphys)
+}
+
+func (d *Device) Uniq() (string, error) {
+	return d.ioctlString(eviocguniq)
+}
+
+func (d *Device) ID() (InputID, error) {
+	var id InputID
+	err := d.ioctlPtr(eviocgid, unsafe.Pointer(&id))
+	return id, err
+}
+
+// DriverVersion returns the evdev protocol version, e.g. 0x010001.
+func (d *Device) DriverVersion() (uint32, error) {
+	var v uint32
+	err := d.ioctlPtr(eviocgversion, unsafe.Pointer(&v))
+	return v, err
+}
@@ -0,0 +1,12 @@
+//go:build !mips && !mipsle && !mips64 && !mips64le && !ppc64 && !ppc64le
+// +build !mips,!mipsle,!mips64,!mips64le,!ppc64,!ppc64le
+
+package ioctl
+
+const (
+	None  = 0
+	Write = 1
+	Read  = 2
+
+	sizeBits = 14
+)
@@ -0,0 +1,13 @@
+//go:build mips || mipsle || mips64 || mips64le || ppc64 || ppc64le
+// +build mips mipsle mips64 mips64le ppc64 ppc64le
+
+package ioctl
+
+// MIPS and PowerPC use three direction bits and a 13-bit size field.
+const (
+	None  = 1
+	Read  = 2
+	Write = 4
+
+	sizeBits = 13
+)
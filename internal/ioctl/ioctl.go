@@ -0,0 +1,67 @@
+// Package ioctl encodes Linux ioctl request numbers and hides the ioctl
+// system call behind an interface so that callers can be tested without
+// a kernel.
+package ioctl
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	nrBits   = 8
+	typeBits = 8
+
+	nrShift   = 0
+	typeShift = nrShift + nrBits
+	sizeShift = typeShift + typeBits
+	dirShift  = sizeShift + sizeBits
+)
+
+func IOC(dir, t, nr, size uintptr) uintptr {
+	return dir<<dirShift | t<<typeShift | nr<<nrShift | size<<sizeShift
+}
+
+func IO(t, nr uintptr) uintptr {
+	return IOC(None, t, nr, 0)
+}
+
+func IOR(t, nr, size uintptr) uintptr {
+	return IOC(Read, t, nr, size)
+}
+
+func IOW(t, nr, size uintptr) uintptr {
+	return IOC(Write, t, nr, size)
+}
+
+func IOWR(t, nr, size uintptr) uintptr {
+	return IOC(Read|Write, t, nr, size)
+}
+
+// Interface performs ioctl requests. Ioctl passes a pointer argument and
+// IoctlInt an integer one, as some requests expect.
+type Interface interface {
+	Ioctl(fd, req uintptr, arg unsafe.Pointer) error
+	IoctlInt(fd, req, arg uintptr) error
+}
+
+// Syscall is the Interface backed by the real system call.
+var Syscall Interface = sysIoctl{}
+
+type sysIoctl struct{}
+
+func (sysIoctl) Ioctl(fd, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+func (sysIoctl) IoctlInt(fd, req, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
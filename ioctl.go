@@ -0,0 +1,66 @@
+package ievio
+
+import (
+	"bytes"
+	"syscall"
+	"unsafe"
+
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+var (
+	eviocgversion = ioctl.IOR('E', 0x01, unsafe.Sizeof(int32(0)))
+	eviocgid      = ioctl.IOR('E', 0x02, unsafe.Sizeof(InputID{}))
+)
+
+func eviocgname(size uintptr) uintptr {
+	return ioctl.IOC(ioctl.Read, 'E', 0x06, size)
+}
+
+func eviocgphys(size uintptr) uintptr {
+	return ioctl.IOC(ioctl.Read, 'E', 0x07, size)
+}
+
+func eviocguniq(size uintptr) uintptr {
+	return ioctl.IOC(ioctl.Read, 'E', 0x08, size)
+}
+
+func (d *Device) ioctlPtr(req uintptr, arg unsafe.Pointer) error {
+	var err error
+	if cerr := d.rc.Control(func(fd uintptr) {
+		err = d.ioctl.Ioctl(fd, req, arg)
+	}); cerr != nil {
+		return cerr
+	}
+
+	return err
+}
+
+func (d *Device) ioctlInt(req, arg uintptr) error {
+	var err error
+	if cerr := d.rc.Control(func(fd uintptr) {
+		err = d.ioctl.IoctlInt(fd, req, arg)
+	}); cerr != nil {
+		return cerr
+	}
+
+	return err
+}
+
+// ioctlString reads a NUL-terminated string. Devices without the string,
+// e.g. without a unique id, report ENOENT, which is returned as "".
+func (d *Device) ioctlString(req func(uintptr) uintptr) (string, error) {
+	buf := make([]byte, 256)
+	if err := d.ioctlPtr(req(uintptr(len(buf))), unsafe.Pointer(&buf[0])); err != nil {
+		if err == syscall.ENOENT {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[:i]
+	}
+
+	return string(buf), nil
+}
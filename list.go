@@ -0,0 +1,340 @@
+package ievio
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DevInputDir = "/dev/input"
+	SysInputDir = "/sys/class/input"
+)
+
+var errUnknownWordSize = errors.New("ievio: unknown kernel word size")
+
+// DeviceEntry describes an evdev node found by List.
+type DeviceEntry struct {
+	Path         string
+	SysPath      string
+	Info         DeviceInfo
+	Capabilities *Capabilities
+	ByID         []string
+	ByPath       []string
+}
+
+func (e *DeviceEntry) Open() (*Device, error) {
+	return Open(e.Path)
+}
+
+func (e *DeviceEntry) OpenFile(flag int) (*Device, error) {
+	return OpenFile(e.Path, flag)
+}
+
+// List returns the event devices of the system, ordered by node number.
+func List() ([]*DeviceEntry, error) {
+	return ListRoot("/")
+}
+
+// ListRoot is like List, with /dev and /sys looked up below root.
+// Identity and capabilities are read from sysfs, which needs no access
+// to the device node; the node is only opened if sysfs is unavailable,
+// and devices that can be read neither way are skipped.
+func ListRoot(root string) ([]*DeviceEntry, error) {
+	devDir := filepath.Join(root, DevInputDir)
+	paths, err := filepath.Glob(filepath.Join(devDir, "event*"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		return eventNumber(paths[i]) < eventNumber(paths[j])
+	})
+
+	byID := readLinks(filepath.Join(devDir, "by-id"))
+	byPath := readLinks(filepath.Join(devDir, "by-path"))
+
+	var entries []*DeviceEntry
+	for _, p := range paths {
+		e, err := readEntry(root, p)
+		if err != nil {
+			continue
+		}
+
+		e.ByID = byID[p]
+		e.ByPath = byPath[p]
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func eventNumber(p string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(p), "event"))
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// readLinks maps device nodes to the symlinks in dir that point to them.
+func readLinks(dir string) map[string][]string {
+	links := map[string][]string{}
+	names, _ := filepath.Glob(filepath.Join(dir, "*"))
+	for _, name := range names {
+		target, err := os.Readlink(name)
+		if err != nil {
+			continue
+		}
+
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		links[filepath.Clean(target)] = append(links[filepath.Clean(target)], name)
+	}
+
+	return links
+}
+
+func readEntry(root, p string) (*DeviceEntry, error) {
+	e := &DeviceEntry{
+		Path:    p,
+		SysPath: filepath.Join(root, SysInputDir, filepath.Base(p)),
+	}
+
+	if err := e.readSysfs(); err == nil {
+		return e, nil
+	}
+
+	d, err := Open(p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer d.Close()
+	info, err := d.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	caps, err := d.Capabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	e.Info = *info
+	e.Capabilities = caps
+	return e, nil
+}
+
+func (e *DeviceEntry) readSysfs() error {
+	dir := filepath.Join(e.SysPath, "device")
+	name, err := readSysString(filepath.Join(dir, "name"))
+	if err != nil {
+		return err
+	}
+
+	e.Info.Name = name
+	e.Info.Phys, _ = readSysString(filepath.Join(dir, "phys"))
+	e.Info.Uniq, _ = readSysString(filepath.Join(dir, "uniq"))
+
+	var id [4]uint16
+	for i, file := range []string{"bustype", "vendor", "product", "version"} {
+		s, err := readSysString(filepath.Join(dir, "id", file))
+		if err != nil {
+			return err
+		}
+
+		v, err := strconv.ParseUint(s, 16, 16)
+		if err != nil {
+			return err
+		}
+		id[i] = uint16(v)
+	}
+	e.Info.ID = InputID{BusType: BUS_TYPE(id[0]), Vendor: id[1], Product: id[2], Version: id[3]}
+
+	// Without the kernel's word size the bitmaps cannot be read; EVIOCGBIT
+	// converts them for 32-bit programs instead.
+	word := kernelLongBits()
+	if word == 0 {
+		return errUnknownWordSize
+	}
+
+	capDir := filepath.Join(dir, "capabilities")
+	s, err := readSysString(filepath.Join(capDir, "ev"))
+	if err != nil {
+		return err
+	}
+
+	types, err := parseSysBitmap(s, EV_CNT, word)
+	if err != nil {
+		return err
+	}
+
+	e.Capabilities = &Capabilities{
+		Types: types,
+		Bits:  map[EV_TYPE]Bitset{},
+	}
+	for _, eventType := range bitTypes {
+		if !types.Has(uint16(eventType)) {
+			continue
+		}
+
+		s, err := readSysString(filepath.Join(capDir, sysCapFiles[eventType]))
+		if err != nil {
+			return err
+		}
+
+		codes, err := parseSysBitmap(s, codeCounts[eventType], word)
+		if err != nil {
+			return err
+		}
+		e.Capabilities.Bits[eventType] = codes
+	}
+
+	return nil
+}
+
+var sysCapFiles = map[EV_TYPE]string{
+	EV_KEY: "key",
+	EV_REL: "rel",
+	EV_ABS: "abs",
+	EV_MSC: "msc",
+	EV_SW:  "sw",
+	EV_LED: "led",
+	EV_SND: "snd",
+	EV_FF:  "ff",
+}
+
+func readSysString(p string) (string, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// parseSysBitmap parses a sysfs capability bitmap of n bits: hexadecimal
+// longs of word bits separated by spaces, the most significant one first.
+func parseSysBitmap(s string, n, word int) (Bitset, error) {
+	b := NewBitset(n)
+	words := strings.Fields(s)
+	for i := range words {
+		w, err := strconv.ParseUint(words[len(words)-1-i], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		for ; w != 0; w &= w - 1 {
+			bit := i*word + bits.TrailingZeros64(w)
+			if bit < n {
+				b.Set(uint16(bit))
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// Matcher selects devices among the entries returned by List.
+type Matcher func(*DeviceEntry) bool
+
+func First(entries []*DeviceEntry, m Matcher) *DeviceEntry {
+	for _, e := range entries {
+		if m(e) {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func Filter(entries []*DeviceEntry, m Matcher) []*DeviceEntry {
+	var v []*DeviceEntry
+	for _, e := range entries {
+		if m(e) {
+			v = append(v, e)
+		}
+	}
+
+	return v
+}
+
+func All(ms ...Matcher) Matcher {
+	return func(e *DeviceEntry) bool {
+		for _, m := range ms {
+			if !m(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func Any(ms ...Matcher) Matcher {
+	return func(e *DeviceEntry) bool {
+		for _, m := range ms {
+			if m(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func Not(m Matcher) Matcher {
+	return func(e *DeviceEntry) bool {
+		return !m(e)
+	}
+}
+
+// MatchName matches the device name against a path.Match pattern.
+func MatchName(pattern string) Matcher {
+	return func(e *DeviceEntry) bool {
+		ok, _ := path.Match(pattern, e.Info.Name)
+		return ok
+	}
+}
+
+func MatchID(vendor, product uint16) Matcher {
+	return func(e *DeviceEntry) bool {
+		return e.Info.ID.Vendor == vendor && e.Info.ID.Product == product
+	}
+}
+
+// MatchVendorProduct parses "vendor:product" in hexadecimal, as printed
+// by lsusb, e.g. "046d:c52b".
+func MatchVendorProduct(s string) (Matcher, error) {
+	vendor, product, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("ievio: invalid vendor:product %q", s)
+	}
+
+	v, err := strconv.ParseUint(vendor, 16, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := strconv.ParseUint(product, 16, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	return MatchID(uint16(v), uint16(p)), nil
+}
+
+// MatchCapability matches devices supporting eventType and, unless it is
+// nil, code.
+func MatchCapability(eventType EV_TYPE, code Code) Matcher {
+	return func(e *DeviceEntry) bool {
+		return e.Capabilities != nil && e.Capabilities.Supports(eventType, code)
+	}
+}
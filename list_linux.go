@@ -0,0 +1,50 @@
+package ievio
+
+import (
+	"math/bits"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var (
+	kernelLongOnce sync.Once
+	kernelLong     int
+)
+
+// kernelLongBits returns the width of a long in the running kernel, in
+// which sysfs prints its bitmaps, or 0 if it cannot be told. A 32-bit
+// program may run on a 64-bit kernel, e.g. Raspberry Pi OS on arm64.
+func kernelLongBits() int {
+	kernelLongOnce.Do(func() {
+		if bits.UintSize == 64 {
+			kernelLong = 64
+			return
+		}
+
+		var u syscall.Utsname
+		if err := syscall.Uname(&u); err != nil {
+			return
+		}
+
+		var b strings.Builder
+		for _, c := range u.Machine {
+			if c == 0 {
+				break
+			}
+			b.WriteByte(byte(c))
+		}
+
+		switch m := b.String(); {
+		case strings.Contains(m, "64") || m == "s390x":
+			kernelLong = 64
+		case strings.HasPrefix(m, "armv8"):
+			// Reported by 32-bit kernels on ARMv8 CPUs as well as by arm64
+			// kernels to programs running with the linux32 personality.
+		default:
+			kernelLong = 32
+		}
+	})
+
+	return kernelLong
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package ievio
+
+import "math/bits"
+
+func kernelLongBits() int {
+	return bits.UintSize
+}
@@ -0,0 +1,239 @@
+package ievio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSysBitmap(t *testing.T) {
+	for _, c := range []struct {
+		s    string
+		word int
+		want []uint16
+	}{
+		{"0", 64, nil},
+		{"17", 64, []uint16{0, 1, 2, 4}},
+		{"1 0", 64, []uint16{64}},
+		{"1 0", 32, []uint16{32}},
+		{"3 0 8000000000000001", 64, []uint16{0, 63, 128, 129}},
+		{"3 0 80000001", 32, []uint16{0, 31, 64, 65}},
+	} {
+		b, err := parseSysBitmap(c.s, KEY_CNT, c.word)
+		if err != nil {
+			t.Errorf("parseSysBitmap(%q, %d): %v", c.s, c.word, err)
+			continue
+		}
+		if got := b.Bits(); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parseSysBitmap(%q, %d) = %v, want %v", c.s, c.word, got, c.want)
+		}
+	}
+
+	if _, err := parseSysBitmap("xyz", KEY_CNT, 64); err == nil {
+		t.Error("parseSysBitmap accepted a malformed bitmap")
+	}
+}
+
+// sysBitmap prints bits the way the kernel does in sysfs.
+func sysBitmap(word int, set ...int) string {
+	longs := make([]uint64, 1)
+	for _, n := range set {
+		for len(longs) <= n/word {
+			longs = append(longs, 0)
+		}
+		longs[n/word] |= 1 << (n % word)
+	}
+
+	var v []string
+	for i := len(longs) - 1; i >= 0; i-- {
+		v = append(v, fmt.Sprintf("%x", longs[i]))
+	}
+
+	return strings.Join(v, " ")
+}
+
+type fakeSysDevice struct {
+	node   string
+	name   string
+	id     InputID
+	ev     []int
+	key    []int
+	rel    []int
+	byID   string
+	byPath string
+}
+
+func writeFile(t *testing.T, p, data string) {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(data+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func symlink(t *testing.T, target, p string) {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeRoot lays out /dev/input and /sys/class/input below a temporary
+// directory. Nodes are regular files, so only sysfs can describe them.
+func fakeRoot(t *testing.T, devices []fakeSysDevice) string {
+	word := kernelLongBits()
+	if word == 0 {
+		t.Skip("unknown kernel word size")
+	}
+
+	root := t.TempDir()
+	devDir := filepath.Join(root, DevInputDir)
+	for _, d := range devices {
+		writeFile(t, filepath.Join(devDir, d.node), "")
+		if d.byID != "" {
+			symlink(t, "../"+d.node, filepath.Join(devDir, "by-id", d.byID))
+		}
+		if d.byPath != "" {
+			symlink(t, "../"+d.node, filepath.Join(devDir, "by-path", d.byPath))
+		}
+		if d.name == "" {
+			continue
+		}
+
+		dir := filepath.Join(root, SysInputDir, d.node, "device")
+		writeFile(t, filepath.Join(dir, "name"), d.name)
+		writeFile(t, filepath.Join(dir, "phys"), "")
+		writeFile(t, filepath.Join(dir, "uniq"), "")
+		writeFile(t, filepath.Join(dir, "id", "bustype"), fmt.Sprintf("%04x", uint16(d.id.BusType)))
+		writeFile(t, filepath.Join(dir, "id", "vendor"), fmt.Sprintf("%04x", d.id.Vendor))
+		writeFile(t, filepath.Join(dir, "id", "product"), fmt.Sprintf("%04x", d.id.Product))
+		writeFile(t, filepath.Join(dir, "id", "version"), fmt.Sprintf("%04x", d.id.Version))
+		writeFile(t, filepath.Join(dir, "capabilities", "ev"), sysBitmap(word, d.ev...))
+		for eventType, file := range sysCapFiles {
+			var set []int
+			switch eventType {
+			case EV_KEY:
+				set = d.key
+			case EV_REL:
+				set = d.rel
+			}
+			writeFile(t, filepath.Join(dir, "capabilities", file), sysBitmap(word, set...))
+		}
+	}
+
+	return root
+}
+
+func TestListRoot(t *testing.T) {
+	root := fakeRoot(t, []fakeSysDevice{
+		{
+			node: "event10",
+			name: "Logitech USB Receiver Mouse",
+			id:   InputID{BusType: BUS_USB, Vendor: 0x046d, Product: 0xc52b, Version: 0x0111},
+			ev:   []int{int(EV_SYN), int(EV_KEY), int(EV_REL)},
+			key:  []int{BTN_LEFT, BTN_RIGHT, BTN_MIDDLE},
+			rel:  []int{int(REL_X), REL_Y, REL_WHEEL},
+
+			byID:   "usb-Logitech_USB_Receiver-if01-event-mouse",
+			byPath: "pci-0000:00:14.0-usb-0:2:1.1-event-mouse",
+		},
+		{
+			node: "event2",
+			name: "AT Translated Set 2 keyboard",
+			id:   InputID{BusType: BUS_I8042, Vendor: 0x0001, Product: 0x0001, Version: 0xab41},
+			ev:   []int{int(EV_SYN), int(EV_KEY), int(EV_MSC), int(EV_LED), int(EV_REP)},
+			key:  []int{KEY_ESC, KEY_A, KEY_MICMUTE},
+
+			byPath: "platform-i8042-serio-0-event-kbd",
+		},
+		{
+			node: "event0",
+			name: "Power Button",
+			id:   InputID{BusType: BUS_HOST, Product: 0x0001},
+			ev:   []int{int(EV_SYN), int(EV_KEY)},
+			key:  []int{KEY_POWER},
+		},
+		// No sysfs entry, and as a regular file it fails EVIOCGNAME.
+		{node: "event5"},
+	})
+
+	entries, err := ListRoot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nodes []string
+	for _, e := range entries {
+		nodes = append(nodes, filepath.Base(e.Path))
+	}
+	if want := []string{"event0", "event2", "event10"}; !reflect.DeepEqual(nodes, want) {
+		t.Fatalf("ListRoot nodes = %v, want %v", nodes, want)
+	}
+
+	mouse := entries[2]
+	if mouse.Info.Name != "Logitech USB Receiver Mouse" || mouse.Info.ID.Vendor != 0x046d || mouse.Info.ID.Version != 0x0111 {
+		t.Errorf("mouse Info = %v", &mouse.Info)
+	}
+	if mouse.SysPath != filepath.Join(root, SysInputDir, "event10") {
+		t.Errorf("mouse SysPath = %q", mouse.SysPath)
+	}
+	if got := mouse.Capabilities.Rels(); !reflect.DeepEqual(got, []REL_CODE{REL_X, REL_Y, REL_WHEEL}) {
+		t.Errorf("mouse Rels() = %v", got)
+	}
+	if got := entries[1].Capabilities.Keys(); !reflect.DeepEqual(got, []KEY_CODE{KEY_ESC, KEY_A, KEY_MICMUTE}) {
+		t.Errorf("keyboard Keys() = %v", got)
+	}
+
+	devDir := filepath.Join(root, DevInputDir)
+	if want := []string{filepath.Join(devDir, "by-id", "usb-Logitech_USB_Receiver-if01-event-mouse")}; !reflect.DeepEqual(mouse.ByID, want) {
+		t.Errorf("mouse ByID = %v, want %v", mouse.ByID, want)
+	}
+	if want := []string{filepath.Join(devDir, "by-path", "pci-0000:00:14.0-usb-0:2:1.1-event-mouse")}; !reflect.DeepEqual(mouse.ByPath, want) {
+		t.Errorf("mouse ByPath = %v, want %v", mouse.ByPath, want)
+	}
+	if len(entries[1].ByID) != 0 || len(entries[1].ByPath) != 1 || len(entries[0].ByPath) != 0 {
+		t.Errorf("links = %v %v %v", entries[0].ByPath, entries[1].ByID, entries[1].ByPath)
+	}
+
+	vp, err := MatchVendorProduct("046d:c52b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MatchVendorProduct("046dc52b"); err == nil {
+		t.Error("MatchVendorProduct accepted a string without a colon")
+	}
+
+	for _, c := range []struct {
+		name  string
+		match Matcher
+		want  []string
+	}{
+		{"MatchName", MatchName("*[Kk]eyboard"), []string{"event2"}},
+		{"MatchVendorProduct", vp, []string{"event10"}},
+		{"MatchCapability type", MatchCapability(EV_REL, nil), []string{"event10"}},
+		{"MatchCapability code", MatchCapability(EV_KEY, NewKeyCode(KEY_POWER)), []string{"event0"}},
+		{"All", All(MatchCapability(EV_KEY, nil), Not(MatchCapability(EV_REL, nil))), []string{"event0", "event2"}},
+		{"Any", Any(MatchName("Power*"), vp), []string{"event0", "event10"}},
+	} {
+		var got []string
+		for _, e := range Filter(entries, c.match) {
+			got = append(got, filepath.Base(e.Path))
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: Filter = %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	if e := First(entries, MatchCapability(EV_KEY, nil)); e != entries[0] {
+		t.Errorf("First = %v, want event0", e)
+	}
+	if e := First(entries, MatchCapability(EV_ABS, nil)); e != nil {
+		t.Errorf("First = %v, want nil", e.Path)
+	}
+}
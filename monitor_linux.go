@@ -0,0 +1,458 @@
+package ievio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var ErrMonitorClosed = errors.New("ievio: monitor closed")
+
+type MonitorEventKind int
+
+const (
+	DeviceAdded MonitorEventKind = iota
+	DeviceRemoved
+)
+
+func (v MonitorEventKind) String() string {
+	switch v {
+	case DeviceAdded:
+		return "Added"
+	case DeviceRemoved:
+		return "Removed"
+	}
+
+	return "UNKNOWN"
+}
+
+// MonitorEvent reports a device node appearing or disappearing. Entry is
+// read when the device is added; a removed device keeps the Entry it had
+// when it was added.
+type MonitorEvent struct {
+	Kind  MonitorEventKind
+	Entry *DeviceEntry
+}
+
+type MonitorOptions struct {
+	// Root is prepended to /dev/input and /sys/class/input, see ListRoot.
+	Root string
+	// Netlink also listens to kernel uevents, which arrive even where
+	// /dev is not a devtmpfs.
+	Netlink bool
+}
+
+// Monitor watches /dev/input for event devices being plugged in and
+// unplugged. A new node is reported once its sysfs entry can be read,
+// which may take udev a moment. It is not safe for concurrent use.
+type Monitor struct {
+	root    string
+	devDir  string
+	files   []*os.File
+	raw     chan notification
+	done    chan struct{}
+	once    sync.Once
+	known   map[string]*DeviceEntry
+	unread  map[string]int // nodes not readable yet, with retries left
+	pending []MonitorEvent
+}
+
+type notification struct {
+	path   string
+	added  bool
+	retry  bool
+	rescan bool
+}
+
+// A node whose entry cannot be read is tried again this many times, then
+// only at the next rescan.
+const (
+	entryRetries    = 10
+	entryRetryDelay = 100 * time.Millisecond
+)
+
+// NewMonitor starts watching and takes a snapshot of the devices already
+// present, available from Devices. A nil opts watches the real system.
+func NewMonitor(opts *MonitorOptions) (*Monitor, error) {
+	root := "/"
+	netlink := false
+	if opts != nil {
+		if opts.Root != "" {
+			root = opts.Root
+		}
+		netlink = opts.Netlink
+	}
+
+	m := &Monitor{
+		root:   root,
+		devDir: filepath.Join(root, DevInputDir),
+		raw:    make(chan notification, 64),
+		done:   make(chan struct{}),
+		known:  map[string]*DeviceEntry{},
+		unread: map[string]int{},
+	}
+
+	ino, err := m.openInotify()
+	if err != nil {
+		return nil, err
+	}
+	m.files = append(m.files, ino)
+
+	if netlink {
+		nl, err := openUevent()
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		m.files = append(m.files, nl)
+	}
+
+	entries, err := ListRoot(root)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	for _, e := range entries {
+		m.known[e.Path] = e
+	}
+	m.rescan()
+
+	go m.readInotify(ino)
+	if netlink {
+		go m.readUevent(m.files[1])
+	}
+
+	return m, nil
+}
+
+func (m *Monitor) Close() error {
+	var err error
+	m.once.Do(func() {
+		close(m.done)
+		for _, f := range m.files {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	})
+
+	return err
+}
+
+// Devices returns the devices currently known to be present, ordered by
+// node number like List.
+func (m *Monitor) Devices() []*DeviceEntry {
+	var v []*DeviceEntry
+	for _, e := range m.known {
+		v = append(v, e)
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		return eventNumber(v[i].Path) < eventNumber(v[j].Path)
+	})
+	return v
+}
+
+// Next blocks until a device is added or removed, ctx is done or m is
+// closed.
+func (m *Monitor) Next(ctx context.Context) (*MonitorEvent, error) {
+	for len(m.pending) == 0 {
+		select {
+		case n := <-m.raw:
+			m.apply(n)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-m.done:
+			return nil, ErrMonitorClosed
+		}
+	}
+
+	ev := m.pending[0]
+	m.pending = m.pending[1:]
+	return &ev, nil
+}
+
+// Events calls handler for every change until an error occurs or ctx is
+// done, in which case ctx.Err() is returned.
+func (m *Monitor) Events(ctx context.Context, handler func(*MonitorEvent)) error {
+	for {
+		ev, err := m.Next(ctx)
+		if err != nil {
+			return err
+		}
+
+		handler(ev)
+	}
+}
+
+func (m *Monitor) apply(n notification) {
+	switch {
+	case n.rescan:
+		m.rescan()
+	case n.retry:
+		if _, ok := m.unread[n.path]; ok {
+			m.add(n.path)
+		}
+	case n.added:
+		m.add(n.path)
+	default:
+		m.remove(n.path)
+	}
+}
+
+func (m *Monitor) add(p string) {
+	if _, ok := m.known[p]; ok {
+		return
+	}
+
+	e, err := readEntry(m.root, p)
+	if err != nil {
+		// The sysfs entry or the permissions may not be there yet.
+		left, ok := m.unread[p]
+		if !ok {
+			left = entryRetries
+		}
+		if left > 0 {
+			left--
+			time.AfterFunc(entryRetryDelay, func() {
+				m.notify(notification{path: p, retry: true})
+			})
+		}
+		m.unread[p] = left
+		return
+	}
+
+	delete(m.unread, p)
+	m.known[p] = e
+	m.pending = append(m.pending, MonitorEvent{Kind: DeviceAdded, Entry: e})
+}
+
+func (m *Monitor) remove(p string) {
+	delete(m.unread, p)
+	e, ok := m.known[p]
+	if !ok {
+		return
+	}
+
+	delete(m.known, p)
+	m.pending = append(m.pending, MonitorEvent{Kind: DeviceRemoved, Entry: e})
+}
+
+// rescan compares the known devices with /dev/input after notifications
+// have been lost.
+func (m *Monitor) rescan() {
+	paths, _ := filepath.Glob(filepath.Join(m.devDir, "event*"))
+	present := map[string]bool{}
+	for _, p := range paths {
+		present[p] = true
+	}
+
+	for p := range m.known {
+		if !present[p] {
+			m.remove(p)
+		}
+	}
+	for p := range m.unread {
+		if !present[p] {
+			delete(m.unread, p)
+		}
+	}
+
+	for _, p := range paths {
+		m.add(p)
+	}
+}
+
+func (m *Monitor) notify(n notification) bool {
+	select {
+	case m.raw <- n:
+		return true
+	case <-m.done:
+		return false
+	}
+}
+
+func (m *Monitor) openInotify() (*os.File, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	mask := uint32(syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO)
+	if _, err := syscall.InotifyAddWatch(fd, m.devDir, mask); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), "inotify"), nil
+}
+
+func (m *Monitor) readInotify(f *os.File) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			mask := binary.NativeEndian.Uint32(buf[off+4:])
+			size := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			name := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+size]
+			off += syscall.SizeofInotifyEvent + size
+			if i := bytes.IndexByte(name, 0); i >= 0 {
+				name = name[:i]
+			}
+
+			var v notification
+			switch {
+			case mask&syscall.IN_Q_OVERFLOW != 0:
+				v.rescan = true
+			case !strings.HasPrefix(string(name), "event"):
+				continue
+			default:
+				v.path = filepath.Join(m.devDir, string(name))
+				v.added = mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0
+			}
+
+			if !m.notify(v) {
+				return
+			}
+		}
+	}
+}
+
+func openUevent() (*os.File, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC|syscall.SOCK_NONBLOCK, syscall.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group 1 carries the kernel's own uevents.
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: 1}); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), "uevent"), nil
+}
+
+// readUevent parses kernel uevents, NUL-separated "KEY=value" fields
+// after an "action@devpath" header, keeping those of event devices.
+func (m *Monitor) readUevent(f *os.File) {
+	buf := make([]byte, 8192)
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			return
+		}
+
+		var action, subsystem, devname string
+		for _, field := range bytes.Split(buf[:n], []byte{0}) {
+			key, value, _ := strings.Cut(string(field), "=")
+			switch key {
+			case "ACTION":
+				action = value
+			case "SUBSYSTEM":
+				subsystem = value
+			case "DEVNAME":
+				devname = value
+			}
+		}
+
+		if subsystem != "input" || !strings.HasPrefix(filepath.Base(devname), "event") {
+			continue
+		}
+		if action != "add" && action != "remove" {
+			continue
+		}
+
+		v := notification{
+			path:  filepath.Join(m.root, "dev", devname),
+			added: action == "add",
+		}
+		if !m.notify(v) {
+			return
+		}
+	}
+}
+
+// MatchIdentity matches devices with the same name, input_id and unique
+// id as info, wherever they are plugged in.
+func MatchIdentity(info DeviceInfo) Matcher {
+	return func(e *DeviceEntry) bool {
+		return e.Info.Name == info.Name && e.Info.ID == info.ID && e.Info.Uniq == info.Uniq
+	}
+}
+
+// Follow reads events from a device matching match and, whenever that
+// device goes away, carries on with another matching one, waiting for it
+// to be plugged in if need be. It returns when ctx is done, m is closed or
+// a device fails for another reason than being unplugged. Follow consumes
+// the events of m, which must not be used by anyone else.
+func (m *Monitor) Follow(ctx context.Context, match Matcher, handler func(*InputEvent)) error {
+	// gone holds the nodes that were lost, which stay among the known
+	// devices until their removal has been seen.
+	gone := map[string]bool{}
+	alive := func(e *DeviceEntry) bool {
+		return !gone[e.Path]
+	}
+
+	for {
+		entry := First(m.Devices(), All(match, alive))
+		if entry == nil {
+			ev, err := m.Next(ctx)
+			if err != nil {
+				return err
+			}
+			delete(gone, ev.Entry.Path)
+			continue
+		}
+
+		d, err := openRetry(ctx, entry.Path)
+		if err == nil {
+			err = d.Events(ctx, handler)
+			d.Close()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !isUnplugged(err) {
+			return err
+		}
+
+		gone[entry.Path] = true
+	}
+}
+
+func isUnplugged(err error) bool {
+	return errors.Is(err, syscall.ENODEV) || errors.Is(err, os.ErrNotExist)
+}
+
+// openRetry gives udev a moment to set the permissions of a node that has
+// just been created.
+func openRetry(ctx context.Context, p string) (*Device, error) {
+	var err error
+	for i := 0; i < 10; i++ {
+		var d *Device
+		if d, err = Open(p); err == nil || !os.IsPermission(err) {
+			return d, err
+		}
+
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, err
+}
@@ -0,0 +1,217 @@
+package ievio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeNode fills a fake device node with one EV_MSC event per value.
+func writeNode(t *testing.T, p string, values ...int32) {
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := NewWriter(f, nil)
+	for _, v := range values {
+		if err := w.WriteEvent(InputEvent{Type: EV_MSC, Code: NewMscCode(MSC_SCAN), Value: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var scanner = fakeSysDevice{
+	name: "Barcode Scanner",
+	id:   InputID{BusType: BUS_USB, Vendor: 0x05e0, Product: 0x1200},
+	ev:   []int{int(EV_SYN), int(EV_KEY), int(EV_MSC)},
+	key:  []int{KEY_ENTER},
+}
+
+func withNode(d fakeSysDevice, node string) fakeSysDevice {
+	d.node = node
+	return d
+}
+
+func TestMonitor(t *testing.T) {
+	root := fakeRoot(t, []fakeSysDevice{withNode(scanner, "event10"), withNode(scanner, "event2"), withNode(scanner, "event3")})
+	devDir := filepath.Join(root, DevInputDir)
+
+	// event3 is plugged in later; its sysfs entry is already there.
+	os.Remove(filepath.Join(devDir, "event3"))
+
+	m, err := NewMonitor(&MonitorOptions{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	var nodes []string
+	for _, e := range m.Devices() {
+		nodes = append(nodes, filepath.Base(e.Path))
+	}
+	if want := []string{"event2", "event10"}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("Devices() = %v, want %v", nodes, want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	writeNode(t, filepath.Join(devDir, "js0"))
+	writeNode(t, filepath.Join(devDir, "event3"))
+	os.Remove(filepath.Join(devDir, "event2"))
+	for _, want := range []MonitorEvent{
+		{DeviceAdded, &DeviceEntry{Path: filepath.Join(devDir, "event3")}},
+		{DeviceRemoved, &DeviceEntry{Path: filepath.Join(devDir, "event2")}},
+	} {
+		ev, err := m.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Kind != want.Kind || ev.Entry.Path != want.Entry.Path {
+			t.Errorf("Next() = %v %s, want %v %s", ev.Kind, ev.Entry.Path, want.Kind, want.Entry.Path)
+		}
+	}
+
+	m.Close()
+	if _, err := m.Next(ctx); err != ErrMonitorClosed {
+		t.Errorf("Next() after Close = %v, want ErrMonitorClosed", err)
+	}
+}
+
+// TestMonitorLateSysfs plugs in a node before its sysfs entry exists, as
+// happens while udev is still at work.
+func TestMonitorLateSysfs(t *testing.T) {
+	root := fakeRoot(t, []fakeSysDevice{withNode(scanner, "event7")})
+	devDir := filepath.Join(root, DevInputDir)
+	sysDir := filepath.Join(root, SysInputDir, "event7")
+	tmpSys := filepath.Join(root, "event7.sys")
+	os.Remove(filepath.Join(devDir, "event7"))
+	if err := os.Rename(sysDir, tmpSys); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMonitor(&MonitorOptions{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	writeNode(t, filepath.Join(devDir, "event7"))
+	short, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if ev, err := m.Next(short); err != context.DeadlineExceeded {
+		t.Fatalf("Next() without sysfs = %v, %v, want context.DeadlineExceeded", ev, err)
+	}
+	if n := len(m.Devices()); n != 0 {
+		t.Errorf("len(Devices()) without sysfs = %d, want 0", n)
+	}
+
+	if err := os.Rename(tmpSys, sysDir); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ev, err := m.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Kind != DeviceAdded || ev.Entry.Info.Name != scanner.name || ev.Entry.Info.ID != scanner.id {
+		t.Errorf("Next() = %v %+v, want Added %s", ev.Kind, ev.Entry.Info, scanner.name)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	keyboard := fakeSysDevice{
+		node: "event1",
+		name: "Keyboard",
+		id:   InputID{BusType: BUS_USB, Vendor: 0x046d, Product: 0xc31c},
+		ev:   []int{int(EV_SYN), int(EV_KEY), int(EV_MSC)},
+		key:  []int{KEY_ENTER},
+	}
+	root := fakeRoot(t, []fakeSysDevice{keyboard, withNode(scanner, "event4"), withNode(scanner, "event3"), withNode(scanner, "event6")})
+	devDir := filepath.Join(root, DevInputDir)
+	writeNode(t, filepath.Join(devDir, "event1"), 100)
+	writeNode(t, filepath.Join(devDir, "event3"), 1)
+	writeNode(t, filepath.Join(devDir, "event4"), 2)
+
+	// event6 is plugged in later; its sysfs entry is already there.
+	os.Remove(filepath.Join(devDir, "event6"))
+
+	m, err := NewMonitor(&MonitorOptions{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	values := make(chan int32, 16)
+	done := make(chan error, 1)
+	info := DeviceInfo{Name: scanner.name, ID: scanner.id}
+	go func() {
+		done <- m.Follow(ctx, MatchIdentity(info), func(input *InputEvent) {
+			values <- input.Value
+		})
+	}()
+
+	// A regular file ends like an unplugged device, so Follow moves on to
+	// the other scanner that is already present, then waits for a new one.
+	next := func() int32 {
+		select {
+		case v := <-values:
+			return v
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for an event")
+		}
+		return 0
+	}
+	if got := []int32{next(), next()}; !reflect.DeepEqual(got, []int32{1, 2}) {
+		t.Errorf("events = %v, want [1 2]", got)
+	}
+
+	tmp := filepath.Join(root, "event6")
+	writeNode(t, tmp, 3)
+	if err := os.Rename(tmp, filepath.Join(devDir, "event6")); err != nil {
+		t.Fatal(err)
+	}
+	if v := next(); v != 3 {
+		t.Errorf("event from the new device = %d, want 3", v)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Follow() = %v, want context.Canceled", err)
+	}
+}
+
+func TestFollowError(t *testing.T) {
+	root := fakeRoot(t, []fakeSysDevice{withNode(scanner, "event3")})
+
+	// Reading a directory fails with EISDIR, which is not an unplug.
+	p := filepath.Join(root, DevInputDir, "event3")
+	os.Remove(p)
+	if err := os.Mkdir(p, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMonitor(&MonitorOptions{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = m.Follow(ctx, MatchName(scanner.name), func(*InputEvent) {})
+	if !errors.Is(err, syscall.EISDIR) {
+		t.Errorf("Follow() = %v, want EISDIR", err)
+	}
+}
@@ -0,0 +1,297 @@
+package ievio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+)
+
+var ErrMuxClosed = errors.New("ievio: mux closed")
+
+// DeviceError reports a failed read on a device registered with a Mux,
+// typically ENODEV once it has been unplugged. The device has already
+// been removed from the Mux, but it is left open.
+type DeviceError struct {
+	Device *Device
+	Err    error
+}
+
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("ievio: %s: %v", e.Device.Path(), e.Err)
+}
+
+func (e *DeviceError) Unwrap() error {
+	return e.Err
+}
+
+type MuxEvent struct {
+	Device *Device
+	Event  InputEvent
+}
+
+// Mux reads from many devices with a single epoll set. Devices may be
+// added and removed while another goroutine is blocked in Next, but a
+// registered device must not be read from by anything else.
+type Mux struct {
+	ep      *os.File
+	rc      syscall.RawConn
+	mu      sync.Mutex
+	sources map[int32]*muxSource
+	ready   []syscall.EpollEvent
+	pending []MuxEvent
+	errs    []error
+	batch   []InputEvent
+}
+
+type muxSource struct {
+	d  *Device
+	fd int32
+	r  *Reader
+}
+
+func NewMux() (*Mux, error) {
+	fd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	// A non-blocking epoll fd is itself pollable, which lets the runtime
+	// poller wait on it with deadlines instead of tying up a thread.
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	ep := os.NewFile(uintptr(fd), "epoll")
+	rc, err := ep.SyscallConn()
+	if err != nil {
+		ep.Close()
+		return nil, err
+	}
+
+	return &Mux{
+		ep:      ep,
+		rc:      rc,
+		sources: map[int32]*muxSource{},
+		ready:   make([]syscall.EpollEvent, 16),
+		batch:   make([]InputEvent, DefaultBatchSize),
+	}, nil
+}
+
+// Close stops the Mux and wakes up a blocked Next. Registered devices are
+// not closed.
+func (m *Mux) Close() error {
+	return m.ep.Close()
+}
+
+// Add registers d with m. Remove d before closing it.
+func (m *Mux) Add(d *Device) error {
+	fd, err := d.fd()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sources[fd]; ok {
+		return nil
+	}
+
+	err = m.ctl(syscall.EPOLL_CTL_ADD, fd)
+	if err != nil {
+		return err
+	}
+
+	m.sources[fd] = &muxSource{
+		d:  d,
+		fd: fd,
+		r:  NewReader(rawReader{d.rc}, d.Codec()),
+	}
+	return nil
+}
+
+// Remove unregisters d. Events of d already read but not yet returned by
+// Next are still delivered.
+func (m *Mux) Remove(d *Device) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for fd, s := range m.sources {
+		if s.d == d {
+			return m.remove(fd)
+		}
+	}
+
+	return nil
+}
+
+// Devices returns the registered devices.
+func (m *Mux) Devices() []*Device {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var v []*Device
+	for _, s := range m.sources {
+		v = append(v, s.d)
+	}
+
+	return v
+}
+
+func (m *Mux) remove(fd int32) error {
+	delete(m.sources, fd)
+	err := m.ctl(syscall.EPOLL_CTL_DEL, fd)
+
+	// A closed device has already left the epoll set on its own.
+	if err == syscall.EBADF || err == syscall.ENOENT {
+		return nil
+	}
+	return err
+}
+
+func (m *Mux) ctl(op int, fd int32) error {
+	var err error
+	cerr := m.rc.Control(func(ep uintptr) {
+		ev := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: fd}
+		err = syscall.EpollCtl(int(ep), op, int(fd), &ev)
+	})
+	if cerr != nil {
+		return ErrMuxClosed
+	}
+
+	return err
+}
+
+// Next blocks until one of the registered devices has an event, ctx is
+// done or m is closed. A *DeviceError is returned when reading a device
+// fails; Next may be called again to carry on with the others.
+func (m *Mux) Next(ctx context.Context) (*MuxEvent, error) {
+	for len(m.pending) == 0 {
+		// Events read before a failure are delivered first.
+		if len(m.errs) > 0 {
+			err := m.errs[0]
+			m.errs = m.errs[1:]
+			return nil, err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := m.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	ev := m.pending[0]
+	m.pending = m.pending[1:]
+	return &ev, nil
+}
+
+// Events calls handler for every event until an error occurs or ctx is
+// done, in which case ctx.Err() is returned.
+func (m *Mux) Events(ctx context.Context, handler func(*Device, *InputEvent)) error {
+	for {
+		ev, err := m.Next(ctx)
+		if err != nil {
+			return err
+		}
+
+		handler(ev.Device, &ev.Event)
+	}
+}
+
+func (m *Mux) wait(ctx context.Context) error {
+	stop := watchDeadline(ctx, m.ep)
+	defer stop()
+
+	n := 0
+	var err error
+	rerr := m.rc.Read(func(ep uintptr) bool {
+		n, err = syscall.EpollWait(int(ep), m.ready, 0)
+		if err == syscall.EINTR {
+			n, err = 0, nil
+		}
+		return n > 0 || err != nil
+	})
+	if rerr != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// The deadline is only ever set for ctx, so this is Close.
+		return ErrMuxClosed
+	}
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ev := range m.ready[:n] {
+		s, ok := m.sources[ev.Fd]
+		if !ok {
+			continue
+		}
+
+		if err := m.drain(s); err != nil {
+			m.remove(s.fd)
+			m.errs = append(m.errs, &DeviceError{Device: s.d, Err: err})
+		}
+	}
+
+	return nil
+}
+
+// drain decodes everything that can be read from s without blocking.
+func (m *Mux) drain(s *muxSource) error {
+	for {
+		n, err := s.r.ReadEvents(m.batch)
+		for _, ev := range m.batch[:n] {
+			m.pending = append(m.pending, MuxEvent{Device: s.d, Event: ev})
+		}
+
+		if err == syscall.EAGAIN {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Device) fd() (int32, error) {
+	var fd int32
+	err := d.rc.Control(func(v uintptr) {
+		fd = int32(v)
+	})
+
+	return fd, err
+}
+
+// rawReader reads from a non-blocking descriptor without parking on the
+// runtime poller, returning syscall.EAGAIN when nothing is available.
+type rawReader struct {
+	rc syscall.RawConn
+}
+
+func (r rawReader) Read(p []byte) (int, error) {
+	n := 0
+	var err error
+	rerr := r.rc.Read(func(fd uintptr) bool {
+		n, err = syscall.Read(int(fd), p)
+		return err != syscall.EINTR
+	})
+	if rerr != nil {
+		return 0, rerr
+	}
+	if n < 0 {
+		n = 0
+	}
+	if n == 0 && err == nil {
+		return 0, io.EOF
+	}
+
+	return n, err
+}
@@ -0,0 +1,88 @@
+package ievio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestMux(t *testing.T) {
+	m, err := NewMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	a, aw := newPipeDevice(t)
+	b, bw := newPipeDevice(t)
+	c, cw := newPipeDevice(t)
+	for _, d := range []*Device{a, b, c, a} {
+		if err := m.Add(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(m.Devices()); n != 3 {
+		t.Fatalf("len(Devices()) = %d, want 3", n)
+	}
+
+	// a and c fail in the same epoll batch as b has events; their events
+	// come first, then both errors.
+	writeValues(t, aw, 1, 2)
+	aw.Close()
+	writeValues(t, bw, 10)
+	cw.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := map[*Device][]int32{}
+	failed := map[*Device]bool{}
+	for len(failed) < 2 {
+		ev, err := m.Next(ctx)
+		var de *DeviceError
+		switch {
+		case errors.As(err, &de):
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("DeviceError = %v, want io.EOF", err)
+			}
+			failed[de.Device] = true
+		case err != nil:
+			t.Fatal(err)
+		default:
+			if failed[ev.Device] {
+				t.Errorf("event %d after the error of its device", ev.Event.Value)
+			}
+			got[ev.Device] = append(got[ev.Device], ev.Event.Value)
+		}
+	}
+
+	if !failed[a] || !failed[c] {
+		t.Errorf("failed devices: a %v, c %v", failed[a], failed[c])
+	}
+	if len(got[a]) != 2 || got[a][0] != 1 || got[a][1] != 2 || len(got[b]) != 1 || got[b][0] != 10 {
+		t.Errorf("events: a %v, b %v", got[a], got[b])
+	}
+	if ds := m.Devices(); len(ds) != 1 || ds[0] != b {
+		t.Errorf("Devices() after failures = %v, want only b", ds)
+	}
+
+	if err := m.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	writeValues(t, bw, 11)
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+	if ev, err := m.Next(short); err != context.DeadlineExceeded {
+		t.Errorf("Next() after Remove = %v, %v, want DeadlineExceeded", ev, err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		m.Close()
+	}()
+	if _, err := m.Next(ctx); err != ErrMuxClosed {
+		t.Errorf("Next() on a closed Mux = %v, want ErrMuxClosed", err)
+	}
+}
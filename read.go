@@ -1,32 +1,69 @@
 package ievio
 
 import (
-	"os"
+	"context"
+	"io"
+	"time"
 )
 
 func Read(dev string, handler func(*InputEvent)) error {
-	f, err := os.Open(dev)
+	d, err := Open(dev)
 	if err != nil {
 		return err
 	}
 
-	defer f.Close()
-	buf := make([]byte, InputEventSize)
+	defer d.Close()
+	return d.Events(context.Background(), handler)
+}
+
+// ReadEvent blocks until the next event is available or ctx is done.
+// It returns io.EOF once a regular file or pipe has been consumed.
+func (d *Device) ReadEvent(ctx context.Context) (*InputEvent, error) {
+	stop := d.watch(ctx)
+	defer stop()
+	input := InputEvent{}
+	if err := d.readEvent(ctx, &input); err != nil {
+		return nil, err
+	}
+
+	return &input, nil
+}
+
+// Events calls handler for every event read from d until the end of the
+// stream, an error, or ctx is done, in which case ctx.Err() is returned.
+func (d *Device) Events(ctx context.Context, handler func(*InputEvent)) error {
+	stop := d.watch(ctx)
+	defer stop()
 	for {
-		len, err := f.Read(buf)
-		if err != nil {
+		input := InputEvent{}
+		if err := d.readEvent(ctx, &input); err != nil {
+			if err == io.EOF {
+				return nil
+			}
 			return err
 		}
-		if len == 0 {
-			break
-		}
 
-		input, err := makeReadData(buf)
-		if err != nil {
-			return err
-		}
+		handler(&input)
+	}
+}
 
-		handler(input)
+// ReadEvents is the allocation-free counterpart of ReadEvent, see
+// Reader.ReadEvents. Use Close to interrupt a blocked call.
+func (d *Device) ReadEvents(events []InputEvent) (int, error) {
+	d.f.SetReadDeadline(time.Time{})
+	return d.r.ReadEvents(events)
+}
+
+func (d *Device) readEvent(ctx context.Context, input *InputEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := d.r.readEvent(input); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
 	}
 
 	return nil
@@ -0,0 +1,148 @@
+package ievio
+
+import (
+	"fmt"
+	"io"
+)
+
+// TruncatedError is returned when a stream ends in the middle of an
+// input_event record.
+type TruncatedError struct {
+	Len  int // number of trailing bytes
+	Size int // size of a whole record
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("ievio: truncated input_event: %d of %d bytes", e.Len, e.Size)
+}
+
+// maxEmptyReads is the number of consecutive (0, nil) reads after which
+// fill reports io.ErrNoProgress.
+const maxEmptyReads = 100
+
+// Reader decodes input_event records from any io.Reader, such as a pipe,
+// a socket or a capture file. Records split across reads are reassembled.
+type Reader struct {
+	r     io.Reader
+	codec Codec
+	buf   []byte
+	rp    int
+	wp    int
+}
+
+// NewReader returns a Reader decoding with c, or NativeCodec if c is nil.
+func NewReader(r io.Reader, c Codec) *Reader {
+	if c == nil {
+		c = NativeCodec
+	}
+
+	return &Reader{
+		r:     r,
+		codec: c,
+		buf:   make([]byte, DefaultBatchSize*c.Size()),
+	}
+}
+
+func (r *Reader) Codec() Codec {
+	return r.codec
+}
+
+func (r *Reader) SetCodec(c Codec) {
+	if n := DefaultBatchSize * c.Size(); len(r.buf) < n {
+		buf := make([]byte, n)
+		r.wp = copy(buf, r.buf[r.rp:r.wp])
+		r.rp = 0
+		r.buf = buf
+	}
+
+	r.codec = c
+}
+
+// ReadEvent returns the next event, or io.EOF at the end of the stream.
+func (r *Reader) ReadEvent() (*InputEvent, error) {
+	input := InputEvent{}
+	if err := r.readEvent(&input); err != nil {
+		return nil, err
+	}
+
+	return &input, nil
+}
+
+// ReadEvents fills events with as many records as are already buffered,
+// reading from the underlying reader only if there is not a single whole
+// one. It does not allocate.
+func (r *Reader) ReadEvents(events []InputEvent) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	size := r.codec.Size()
+	if r.wp-r.rp < size {
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := 0
+	for n < len(events) && r.wp-r.rp >= size {
+		input, err := r.codec.Decode(r.buf[r.rp : r.rp+size])
+		r.rp += size
+		if err != nil {
+			return n, err
+		}
+
+		events[n] = input
+		n++
+	}
+
+	return n, nil
+}
+
+func (r *Reader) readEvent(input *InputEvent) error {
+	size := r.codec.Size()
+	if r.wp-r.rp < size {
+		if err := r.fill(); err != nil {
+			return err
+		}
+	}
+
+	v, err := r.codec.Decode(r.buf[r.rp : r.rp+size])
+	r.rp += size
+	if err != nil {
+		return err
+	}
+
+	*input = v
+	return nil
+}
+
+// fill reads until at least one whole record is buffered. Partial records
+// left over from a previous read are kept and completed by the next one.
+func (r *Reader) fill() error {
+	if r.rp > 0 {
+		r.wp = copy(r.buf, r.buf[r.rp:r.wp])
+		r.rp = 0
+	}
+
+	size := r.codec.Size()
+	for empty := 0; r.wp < size; {
+		n, err := r.r.Read(r.buf[r.wp:])
+		r.wp += n
+		if n == 0 && err == nil {
+			// Give up on readers that keep returning nothing, as bufio does.
+			if empty++; empty >= maxEmptyReads {
+				return io.ErrNoProgress
+			}
+			continue
+		}
+		empty = 0
+		if err == io.EOF && r.wp > 0 && r.wp < size {
+			return &TruncatedError{Len: r.wp, Size: size}
+		}
+		if err != nil && r.wp < size {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package ievio
+
+import (
+	"io"
+	"testing"
+)
+
+type emptyReader struct{}
+
+func (emptyReader) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+func TestReaderNoProgress(t *testing.T) {
+	r := NewReader(emptyReader{}, nil)
+	if _, err := r.ReadEvent(); err != io.ErrNoProgress {
+		t.Errorf("ReadEvent = %v, want io.ErrNoProgress", err)
+	}
+}
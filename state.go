@@ -0,0 +1,49 @@
+package ievio
+
+const (
+	eviocgkey = 0x18
+	eviocgled = 0x19
+	eviocgsnd = 0x1a
+	eviocgsw  = 0x1b
+)
+
+// KeyState returns the keys and buttons currently held down.
+func (d *Device) KeyState() ([]KEY_CODE, error) {
+	b, err := d.bitmap(eviocgkey, KEY_CNT)
+	if err != nil {
+		return nil, err
+	}
+
+	return bitsAs[KEY_CODE](b), nil
+}
+
+// LEDState returns the LEDs currently lit.
+func (d *Device) LEDState() ([]LED_CODE, error) {
+	b, err := d.bitmap(eviocgled, LED_CNT)
+	if err != nil {
+		return nil, err
+	}
+
+	return bitsAs[LED_CODE](b), nil
+}
+
+// SwitchState returns the switches currently on, e.g. SW_LID when the
+// lid is closed.
+func (d *Device) SwitchState() ([]SW_CODE, error) {
+	b, err := d.bitmap(eviocgsw, SW_CNT)
+	if err != nil {
+		return nil, err
+	}
+
+	return bitsAs[SW_CODE](b), nil
+}
+
+// SoundState returns the sounds currently playing.
+func (d *Device) SoundState() ([]SND_CODE, error) {
+	b, err := d.bitmap(eviocgsnd, SND_CNT)
+	if err != nil {
+		return nil, err
+	}
+
+	return bitsAs[SND_CODE](b), nil
+}
@@ -0,0 +1,84 @@
+package ievio
+
+import (
+	"context"
+	"io"
+)
+
+// OverflowPolicy decides what Stream does with a new event when the
+// consumer has not drained the events channel yet.
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // wait for the consumer
+	OverflowDropOldest                       // discard the oldest buffered event
+	OverflowDropNewest                       // discard the event just read
+)
+
+const DefaultStreamBufferSize = 64
+
+type StreamOptions struct {
+	BufferSize int
+	Overflow   OverflowPolicy
+}
+
+// Stream reads events from d in a separate goroutine until ctx is done,
+// a read fails or the stream ends. Both channels are closed when the
+// goroutine exits; the error channel receives the terminating error,
+// ctx.Err() included, and nothing at a clean end of stream.
+// A nil opts is equivalent to &StreamOptions{}.
+func (d *Device) Stream(ctx context.Context, opts *StreamOptions) (<-chan InputEvent, <-chan error) {
+	size := DefaultStreamBufferSize
+	overflow := OverflowBlock
+	if opts != nil {
+		if opts.BufferSize > 0 {
+			size = opts.BufferSize
+		}
+		overflow = opts.Overflow
+	}
+
+	events := make(chan InputEvent, size)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(events)
+		stop := d.watch(ctx)
+		defer stop()
+		for {
+			input := InputEvent{}
+			if err := d.readEvent(ctx, &input); err != nil {
+				if err != io.EOF {
+					errc <- err
+				}
+				return
+			}
+
+			switch overflow {
+			case OverflowDropOldest:
+				select {
+				case events <- input:
+				default:
+					select {
+					case <-events:
+					default:
+					}
+					events <- input
+				}
+			case OverflowDropNewest:
+				select {
+				case events <- input:
+				default:
+				}
+			default:
+				select {
+				case events <- input:
+				case <-ctx.Done():
+					errc <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return events, errc
+}
@@ -0,0 +1,78 @@
+package ievio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStreamOverflow(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		overflow OverflowPolicy
+		want     []int32
+	}{
+		{"DropOldest", OverflowDropOldest, []int32{3, 4}},
+		{"DropNewest", OverflowDropNewest, []int32{0, 1}},
+	} {
+		d, w := newPipeDevice(t)
+		writeValues(t, w, 0, 1, 2, 3, 4)
+		w.Close()
+
+		events, errc := d.Stream(context.Background(), &StreamOptions{BufferSize: 2, Overflow: c.overflow})
+
+		// Nothing is read until the stream has ended, so every event past
+		// the first two overflows the buffer.
+		if err, ok := <-errc; ok {
+			t.Errorf("%s: error = %v, want none at the end of the stream", c.name, err)
+		}
+		var got []int32
+		for input := range events {
+			got = append(got, input.Value)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: events = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestStreamBlock(t *testing.T) {
+	d, w := newPipeDevice(t)
+	writeValues(t, w, 0, 1, 2, 3, 4)
+	w.Close()
+
+	events, errc := d.Stream(context.Background(), &StreamOptions{BufferSize: 2})
+	var got []int32
+	for input := range events {
+		got = append(got, input.Value)
+	}
+	if want := []int32{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if err, ok := <-errc; ok {
+		t.Errorf("error = %v, want none at the end of the stream", err)
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	d, _ := newPipeDevice(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	events, errc := d.Stream(ctx, nil)
+	cancel()
+
+	select {
+	case err := <-errc:
+		if err != context.Canceled {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stream did not stop after cancel")
+	}
+	if _, ok := <-events; ok {
+		t.Error("events channel still open after cancel")
+	}
+	if _, ok := <-errc; ok {
+		t.Error("error channel still open after cancel")
+	}
+}
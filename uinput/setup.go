@@ -0,0 +1,108 @@
+package uinput
+
+import (
+	"github.com/niumlaque/ievio"
+)
+
+// Axis declares an absolute axis and its range, see struct input_absinfo.
+type Axis struct {
+	Code       ievio.ABS_CODE
+	Value      int32
+	Min        int32
+	Max        int32
+	Fuzz       int32
+	Flat       int32
+	Resolution int32
+}
+
+// Setup declares the identity and the capabilities of a virtual device.
+// Event types are enabled implicitly by the codes listed for them. Force
+// feedback cannot be declared, as the device would have to serve the
+// effect uploads of its clients.
+type Setup struct {
+	Name string
+	Phys string
+	ID   ievio.InputID
+
+	Keys     []ievio.KEY_CODE
+	Rels     []ievio.REL_CODE
+	Axes     []Axis
+	Miscs    []ievio.MSC_CODE
+	Switches []ievio.SW_CODE
+	LEDs     []ievio.LED_CODE
+	Sounds   []ievio.SND_CODE
+	Props    []ievio.INPUT_PROP
+
+	// Repeat lets the kernel generate autorepeat for Keys.
+	Repeat bool
+}
+
+func Keyboard(name string) *Setup {
+	s := &Setup{
+		Name:   name,
+		ID:     ievio.InputID{BusType: ievio.BUS_VIRTUAL},
+		Miscs:  []ievio.MSC_CODE{ievio.MSC_SCAN},
+		LEDs:   []ievio.LED_CODE{ievio.LED_NUML, ievio.LED_CAPSL, ievio.LED_SCROLLL},
+		Repeat: true,
+	}
+
+	for k := ievio.KEY_CODE(ievio.KEY_ESC); k <= ievio.KEY_MICMUTE; k++ {
+		s.Keys = append(s.Keys, k)
+	}
+
+	return s
+}
+
+func Mouse(name string) *Setup {
+	return &Setup{
+		Name: name,
+		ID:   ievio.InputID{BusType: ievio.BUS_VIRTUAL},
+		Keys: []ievio.KEY_CODE{
+			ievio.BTN_LEFT, ievio.BTN_RIGHT, ievio.BTN_MIDDLE, ievio.BTN_SIDE, ievio.BTN_EXTRA,
+		},
+		Rels: []ievio.REL_CODE{
+			ievio.REL_X, ievio.REL_Y, ievio.REL_WHEEL, ievio.REL_HWHEEL,
+		},
+		Props: []ievio.INPUT_PROP{ievio.INPUT_PROP_POINTER},
+	}
+}
+
+func Gamepad(name string) *Setup {
+	return &Setup{
+		Name: name,
+		ID:   ievio.InputID{BusType: ievio.BUS_VIRTUAL},
+		Keys: []ievio.KEY_CODE{
+			ievio.BTN_SOUTH, ievio.BTN_EAST, ievio.BTN_NORTH, ievio.BTN_WEST,
+			ievio.BTN_TL, ievio.BTN_TR, ievio.BTN_SELECT, ievio.BTN_START, ievio.BTN_MODE,
+			ievio.BTN_THUMBL, ievio.BTN_THUMBR,
+			ievio.BTN_DPAD_UP, ievio.BTN_DPAD_DOWN, ievio.BTN_DPAD_LEFT, ievio.BTN_DPAD_RIGHT,
+		},
+		Axes: []Axis{
+			{Code: ievio.ABS_X, Min: -32768, Max: 32767, Fuzz: 16, Flat: 128},
+			{Code: ievio.ABS_Y, Min: -32768, Max: 32767, Fuzz: 16, Flat: 128},
+			{Code: ievio.ABS_RX, Min: -32768, Max: 32767, Fuzz: 16, Flat: 128},
+			{Code: ievio.ABS_RY, Min: -32768, Max: 32767, Fuzz: 16, Flat: 128},
+			{Code: ievio.ABS_Z, Min: 0, Max: 255},
+			{Code: ievio.ABS_RZ, Min: 0, Max: 255},
+		},
+	}
+}
+
+// Touchscreen declares a multi-touch screen of width x height units with
+// the given number of slots.
+func Touchscreen(name string, width, height, slots int32) *Setup {
+	return &Setup{
+		Name: name,
+		ID:   ievio.InputID{BusType: ievio.BUS_VIRTUAL},
+		Keys: []ievio.KEY_CODE{ievio.BTN_TOUCH},
+		Axes: []Axis{
+			{Code: ievio.ABS_X, Max: width - 1},
+			{Code: ievio.ABS_Y, Max: height - 1},
+			{Code: ievio.ABS_MT_SLOT, Max: slots - 1},
+			{Code: ievio.ABS_MT_POSITION_X, Max: width - 1},
+			{Code: ievio.ABS_MT_POSITION_Y, Max: height - 1},
+			{Code: ievio.ABS_MT_TRACKING_ID, Max: 0xffff},
+		},
+		Props: []ievio.INPUT_PROP{ievio.INPUT_PROP_DIRECT},
+	}
+}
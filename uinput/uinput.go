@@ -0,0 +1,267 @@
+// Package uinput creates virtual input devices through /dev/uinput.
+// Events written to them are delivered by the kernel like those of real
+// hardware, unlike events written to an existing /dev/input/eventN node.
+package uinput
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/niumlaque/ievio"
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+const DefaultPath = "/dev/uinput"
+
+const nameSize = 80
+
+var ErrNameTooLong = errors.New("uinput: device name too long")
+
+var (
+	uiDevCreate  = ioctl.IO('U', 1)
+	uiDevDestroy = ioctl.IO('U', 2)
+	uiDevSetup   = ioctl.IOW('U', 3, unsafe.Sizeof(uinputSetup{}))
+	uiAbsSetup   = ioctl.IOW('U', 4, unsafe.Sizeof(uinputAbsSetup{}))
+	uiSetEvBit   = ioctl.IOW('U', 100, unsafe.Sizeof(int32(0)))
+	uiSetKeyBit  = ioctl.IOW('U', 101, unsafe.Sizeof(int32(0)))
+	uiSetRelBit  = ioctl.IOW('U', 102, unsafe.Sizeof(int32(0)))
+	uiSetAbsBit  = ioctl.IOW('U', 103, unsafe.Sizeof(int32(0)))
+	uiSetMscBit  = ioctl.IOW('U', 104, unsafe.Sizeof(int32(0)))
+	uiSetLedBit  = ioctl.IOW('U', 105, unsafe.Sizeof(int32(0)))
+	uiSetSndBit  = ioctl.IOW('U', 106, unsafe.Sizeof(int32(0)))
+	uiSetPhys    = ioctl.IOW('U', 108, unsafe.Sizeof(uintptr(0)))
+	uiSetSwBit   = ioctl.IOW('U', 109, unsafe.Sizeof(int32(0)))
+	uiSetPropBit = ioctl.IOW('U', 110, unsafe.Sizeof(int32(0)))
+)
+
+func uiGetSysname(size uintptr) uintptr {
+	return ioctl.IOC(ioctl.Read, 'U', 44, size)
+}
+
+// uinputSetup mirrors struct uinput_setup.
+type uinputSetup struct {
+	id           ievio.InputID
+	name         [nameSize]byte
+	ffEffectsMax uint32
+}
+
+// uinputAbsSetup mirrors struct uinput_abs_setup.
+type uinputAbsSetup struct {
+	code    uint16
+	absinfo ievio.AbsInfo
+}
+
+type Device struct {
+	f     *os.File
+	rc    syscall.RawConn
+	ioctl ioctl.Interface
+	w     *ievio.Writer
+}
+
+// Create opens DefaultPath and creates a virtual device described by s.
+func Create(s *Setup) (*Device, error) {
+	return CreateFile(DefaultPath, s)
+}
+
+func CreateFile(path string, s *Setup) (*Device, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := create(f, ioctl.Syscall, s)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func create(f *os.File, io ioctl.Interface, s *Setup) (*Device, error) {
+	if len(s.Name) >= nameSize {
+		return nil, ErrNameTooLong
+	}
+
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Device{
+		f:     f,
+		rc:    rc,
+		ioctl: io,
+		w:     ievio.NewWriter(f, ievio.NativeCodec),
+	}
+
+	if err := d.declare(s); err != nil {
+		return nil, err
+	}
+
+	setup := uinputSetup{
+		id: s.ID,
+	}
+	copy(setup.name[:], s.Name)
+	if err := d.ioctlPtr(uiDevSetup, unsafe.Pointer(&setup)); err != nil {
+		return nil, err
+	}
+
+	if err := d.ioctlInt(uiDevCreate, 0); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *Device) declare(s *Setup) error {
+	bits := []struct {
+		eventType ievio.EV_TYPE
+		req       uintptr
+		codes     []uint16
+	}{
+		{ievio.EV_KEY, uiSetKeyBit, codesOf(s.Keys)},
+		{ievio.EV_REL, uiSetRelBit, codesOf(s.Rels)},
+		{ievio.EV_MSC, uiSetMscBit, codesOf(s.Miscs)},
+		{ievio.EV_SW, uiSetSwBit, codesOf(s.Switches)},
+		{ievio.EV_LED, uiSetLedBit, codesOf(s.LEDs)},
+		{ievio.EV_SND, uiSetSndBit, codesOf(s.Sounds)},
+	}
+
+	for _, b := range bits {
+		if len(b.codes) == 0 {
+			continue
+		}
+
+		if err := d.ioctlInt(uiSetEvBit, uintptr(b.eventType)); err != nil {
+			return err
+		}
+		for _, code := range b.codes {
+			if err := d.ioctlInt(b.req, uintptr(code)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(s.Axes) > 0 {
+		if err := d.ioctlInt(uiSetEvBit, uintptr(ievio.EV_ABS)); err != nil {
+			return err
+		}
+		for _, axis := range s.Axes {
+			if err := d.ioctlInt(uiSetAbsBit, uintptr(axis.Code)); err != nil {
+				return err
+			}
+
+			abs := uinputAbsSetup{
+				code: uint16(axis.Code),
+				absinfo: ievio.AbsInfo{
+					Value:      axis.Value,
+					Minimum:    axis.Min,
+					Maximum:    axis.Max,
+					Fuzz:       axis.Fuzz,
+					Flat:       axis.Flat,
+					Resolution: axis.Resolution,
+				},
+			}
+			if err := d.ioctlPtr(uiAbsSetup, unsafe.Pointer(&abs)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.Repeat {
+		if err := d.ioctlInt(uiSetEvBit, uintptr(ievio.EV_REP)); err != nil {
+			return err
+		}
+	}
+
+	for _, prop := range s.Props {
+		if err := d.ioctlInt(uiSetPropBit, uintptr(prop)); err != nil {
+			return err
+		}
+	}
+
+	if s.Phys != "" {
+		phys := append([]byte(s.Phys), 0)
+		if err := d.ioctlPtr(uiSetPhys, unsafe.Pointer(&phys[0])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func codesOf[T ~uint16](codes []T) []uint16 {
+	v := make([]uint16, len(codes))
+	for i, code := range codes {
+		v[i] = uint16(code)
+	}
+
+	return v
+}
+
+// SysName returns the name of the device below /sys/devices/virtual/input.
+func (d *Device) SysName() (string, error) {
+	buf := make([]byte, 64)
+	if err := d.ioctlPtr(uiGetSysname(uintptr(len(buf))), unsafe.Pointer(&buf[0])); err != nil {
+		return "", err
+	}
+
+	for i, c := range buf {
+		if c == 0 {
+			return string(buf[:i]), nil
+		}
+	}
+
+	return string(buf), nil
+}
+
+func (d *Device) WriteEvent(input ievio.InputEvent) error {
+	return d.w.WriteEvent(input)
+}
+
+func (d *Device) WriteEvents(events []ievio.InputEvent) error {
+	return d.w.WriteEvents(events)
+}
+
+func (d *Device) WriteFrame(events []ievio.InputEvent) error {
+	return d.w.WriteFrame(events)
+}
+
+func (d *Device) BeginFrame() *ievio.FrameBuilder {
+	return d.w.BeginFrame()
+}
+
+// Close destroys the virtual device and closes the uinput handle.
+func (d *Device) Close() error {
+	err := d.ioctlInt(uiDevDestroy, 0)
+	if cerr := d.f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+func (d *Device) ioctlPtr(req uintptr, arg unsafe.Pointer) error {
+	var err error
+	if cerr := d.rc.Control(func(fd uintptr) {
+		err = d.ioctl.Ioctl(fd, req, arg)
+	}); cerr != nil {
+		return cerr
+	}
+
+	return err
+}
+
+func (d *Device) ioctlInt(req, arg uintptr) error {
+	var err error
+	if cerr := d.rc.Control(func(fd uintptr) {
+		err = d.ioctl.IoctlInt(fd, req, arg)
+	}); cerr != nil {
+		return cerr
+	}
+
+	return err
+}
@@ -0,0 +1,152 @@
+package uinput
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/niumlaque/ievio"
+	"github.com/niumlaque/ievio/internal/ioctl"
+)
+
+type call struct {
+	req uintptr
+	arg uintptr
+}
+
+// fakeIoctl records requests instead of sending them to a kernel, along
+// with copies of the structures passed to UI_DEV_SETUP and UI_ABS_SETUP.
+type fakeIoctl struct {
+	calls []call
+	setup uinputSetup
+	abs   []uinputAbsSetup
+}
+
+func (f *fakeIoctl) Ioctl(fd, req uintptr, arg unsafe.Pointer) error {
+	switch req {
+	case uiDevSetup:
+		f.setup = *(*uinputSetup)(arg)
+	case uiAbsSetup:
+		f.abs = append(f.abs, *(*uinputAbsSetup)(arg))
+	}
+
+	f.calls = append(f.calls, call{req: req})
+	return nil
+}
+
+func (f *fakeIoctl) IoctlInt(fd, req, arg uintptr) error {
+	f.calls = append(f.calls, call{req: req, arg: arg})
+	return nil
+}
+
+func tempFile(t *testing.T) *os.File {
+	f, err := os.CreateTemp(t.TempDir(), "uinput")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestCreate(t *testing.T) {
+	io := &fakeIoctl{}
+	s := &Setup{
+		Name: "test pad",
+		ID:   ievio.InputID{BusType: ievio.BUS_VIRTUAL, Vendor: 0x1234, Product: 0x5678},
+		Keys: []ievio.KEY_CODE{ievio.BTN_SOUTH},
+		Axes: []Axis{{Code: ievio.ABS_X, Min: -32768, Max: 32767, Flat: 128}},
+	}
+
+	if _, err := create(tempFile(t), io, s); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []call{
+		{uiSetEvBit, uintptr(ievio.EV_KEY)},
+		{uiSetKeyBit, uintptr(ievio.BTN_SOUTH)},
+		{uiSetEvBit, uintptr(ievio.EV_ABS)},
+		{uiSetAbsBit, uintptr(ievio.ABS_X)},
+		{uiAbsSetup, 0},
+		{uiDevSetup, 0},
+		{uiDevCreate, 0},
+	}
+	if len(io.calls) != len(want) {
+		t.Fatalf("got %d ioctls %x, want %d %x", len(io.calls), io.calls, len(want), want)
+	}
+	for i := range want {
+		if io.calls[i] != want[i] {
+			t.Errorf("ioctl %d = %x, want %x", i, io.calls[i], want[i])
+		}
+	}
+
+	if len(io.abs) != 1 || io.abs[0].code != uint16(ievio.ABS_X) ||
+		io.abs[0].absinfo.Minimum != -32768 || io.abs[0].absinfo.Maximum != 32767 || io.abs[0].absinfo.Flat != 128 {
+		t.Errorf("UI_ABS_SETUP = %+v", io.abs)
+	}
+
+	if io.setup.id != s.ID {
+		t.Errorf("UI_DEV_SETUP id = %v, want %v", io.setup.id, s.ID)
+	}
+	if name := string(io.setup.name[:len(s.Name)+1]); name != s.Name+"\x00" {
+		t.Errorf("UI_DEV_SETUP name = %q", name)
+	}
+}
+
+func TestCreateNameTooLong(t *testing.T) {
+	io := &fakeIoctl{}
+	if _, err := create(tempFile(t), io, &Setup{Name: strings.Repeat("x", nameSize)}); !errors.Is(err, ErrNameTooLong) {
+		t.Errorf("create with a %d byte name = %v, want ErrNameTooLong", nameSize, err)
+	}
+	if len(io.calls) != 0 {
+		t.Errorf("create with a too long name issued %d ioctls", len(io.calls))
+	}
+
+	// The name must leave room for its terminating NUL.
+	if _, err := create(tempFile(t), io, &Setup{Name: strings.Repeat("x", nameSize-1)}); err != nil {
+		t.Errorf("create with a %d byte name = %v", nameSize-1, err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	io := &fakeIoctl{}
+	d, err := create(tempFile(t), io, &Setup{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	io.calls = nil
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(io.calls) != 1 || io.calls[0].req != uiDevDestroy {
+		t.Errorf("Close issued %x, want UI_DEV_DESTROY", io.calls)
+	}
+}
+
+// TestRequestNumbers compares against the values of linux/uinput.h on
+// architectures with the generic ioctl encoding.
+func TestRequestNumbers(t *testing.T) {
+	if ioctl.Write != 1 {
+		t.Skip("architecture uses a different ioctl encoding")
+	}
+
+	for _, c := range []struct {
+		name      string
+		got, want uintptr
+	}{
+		{"UI_DEV_CREATE", uiDevCreate, 0x5501},
+		{"UI_DEV_DESTROY", uiDevDestroy, 0x5502},
+		{"UI_DEV_SETUP", uiDevSetup, 0x405c5503},
+		{"UI_ABS_SETUP", uiAbsSetup, 0x401c5504},
+		{"UI_SET_EVBIT", uiSetEvBit, 0x40045564},
+		{"UI_SET_KEYBIT", uiSetKeyBit, 0x40045565},
+		{"UI_SET_ABSBIT", uiSetAbsBit, 0x40045567},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %#x, want %#x", c.name, c.got, c.want)
+		}
+	}
+}
@@ -1,26 +1,82 @@
 package ievio
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
+var ErrValueRange = errors.New("ievio: value out of int32 range")
+
+// Write sends a single event to dev. eventType and code are names such as
+// "EV_KEY" and "KEY_A", or numbers; numbers in all three fields are
+// decimal unless prefixed with "0x" for hexadecimal or "0o" for octal, so
+// "010" is 10 and "0o10" is 8.
 func Write(dev, eventType, code, value string) (*InputEvent, error) {
-	f, err := os.OpenFile(dev, os.O_WRONLY|os.O_APPEND, os.ModeAppend|os.ModeCharDevice)
+	d, err := OpenFile(dev, os.O_WRONLY|os.O_APPEND)
 	if err != nil {
 		return nil, err
 	}
 
-	defer f.Close()
-	buf, input, err := makeWriteData(eventType, code, value)
+	defer d.Close()
+	input, err := makeWriteData(eventType, code, value)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err = f.Write(buf); err != nil {
+	if err = d.WriteEvent(*input); err != nil {
 		return nil, err
 	}
 
-	f.Sync()
+	d.f.Sync()
 
 	return input, nil
 }
+
+func makeWriteData(eventType, code, value string) (*InputEvent, error) {
+	input := InputEvent{}
+	if et, err := ParseEventType(eventType); err == nil {
+		input.Type = et
+	} else {
+		return nil, err
+	}
+
+	if cd, err := ParseCode(input.Type, code); err == nil {
+		input.Code = cd
+	} else {
+		return nil, err
+	}
+
+	if v, err := parseValue(value); err == nil {
+		input.Value = v
+	} else {
+		return nil, err
+	}
+
+	input.Time = NewTimeval(time.Now())
+	return &input, nil
+}
+
+// parseValue accepts signed decimal and hexadecimal values, e.g. "-5" or
+// "-0x10", as REL and many ABS axes are signed.
+func parseValue(s string) (int32, error) {
+	v, err := stringToInt64(s, 32)
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+			return 0, fmt.Errorf("%w: %q", ErrValueRange, s)
+		}
+		return 0, err
+	}
+
+	return int32(v), nil
+}
+
+func (d *Device) WriteEvent(input InputEvent) error {
+	return d.w.WriteEvent(input)
+}
+
+func (d *Device) WriteEvents(events []InputEvent) error {
+	return d.w.WriteEvents(events)
+}
@@ -0,0 +1,63 @@
+package ievio
+
+import (
+	"io"
+	"time"
+)
+
+// Writer encodes input_event records to any io.Writer.
+type Writer struct {
+	w     io.Writer
+	codec Codec
+	buf   []byte
+}
+
+// NewWriter returns a Writer encoding with c, or NativeCodec if c is nil.
+func NewWriter(w io.Writer, c Codec) *Writer {
+	if c == nil {
+		c = NativeCodec
+	}
+
+	return &Writer{
+		w:     w,
+		codec: c,
+	}
+}
+
+func (w *Writer) Codec() Codec {
+	return w.codec
+}
+
+func (w *Writer) SetCodec(c Codec) {
+	w.codec = c
+}
+
+func (w *Writer) WriteEvent(input InputEvent) error {
+	return w.WriteEvents([]InputEvent{input})
+}
+
+// WriteEvents encodes all events into a single write, so the kernel never
+// sees only part of them. Events with a zero Time are stamped with the
+// current time.
+func (w *Writer) WriteEvents(events []InputEvent) error {
+	size := w.codec.Size()
+	if cap(w.buf) < len(events)*size {
+		w.buf = make([]byte, len(events)*size)
+	}
+
+	buf := w.buf[:len(events)*size]
+	var now Timeval
+	for i := range events {
+		input := events[i]
+		if input.Time == (Timeval{}) {
+			if now == (Timeval{}) {
+				now = NewTimeval(time.Now())
+			}
+			input.Time = now
+		}
+		w.codec.Encode(input, buf[i*size:])
+	}
+
+	_, err := w.w.Write(buf)
+	return err
+}